@@ -0,0 +1,19 @@
+package basepo
+
+// AggregateNQL 编译成 MATCH <pattern> WHERE ... RETURN fn(prop) AS value，field为nil时是fn(*)；
+// group不为nil时按group分组，多返回一列key。排序和分页对聚合没有意义，不会输出
+func (q *Query) AggregateNQL(pattern, fn string, field, group *Field) string {
+	nql := "MATCH " + pattern
+	if q.cond != nil {
+		nql += " WHERE " + q.cond.nql(q.matchProp)
+	}
+	nql += " RETURN "
+	if group != nil {
+		nql += q.matchProp(*group) + " AS key,"
+	}
+	arg := "*"
+	if field != nil {
+		arg = q.matchProp(*field)
+	}
+	return nql + fn + "(" + arg + ") AS value"
+}
@@ -0,0 +1,53 @@
+package basepo
+
+// Assign UPDATE语句中的一个赋值，值在服务端计算，例如 UserLoginCount.Add(1)
+type Assign struct {
+	field Field
+	expr  string
+}
+
+func (a Assign) Field() Field {
+	return a.field
+}
+
+// NQL SET子句中的 prop = expr
+func (a Assign) NQL() string {
+	return a.field.name + " = " + a.expr
+}
+
+// Set 赋值为字面量
+func (f Field) Set(v interface{}) Assign {
+	return Assign{field: f, expr: Value(v)}
+}
+
+// Add 在原值上增加
+func (f Field) Add(v interface{}) Assign {
+	return Assign{field: f, expr: f.name + " + " + Value(v)}
+}
+
+// Sub 在原值上减少
+func (f Field) Sub(v interface{}) Assign {
+	return Assign{field: f, expr: f.name + " - " + Value(v)}
+}
+
+// Now 赋值为服务端的当前时间戳
+func (f Field) Now() Assign {
+	return Assign{field: f, expr: "now()"}
+}
+
+// Without 去掉fields中的excludes，版本字段和token字段由生成的方法单独写入
+func Without(fields []string, excludes ...string) []string {
+	result := make([]string, 0, len(fields))
+	for _, f := range fields {
+		skip := false
+		for _, e := range excludes {
+			if f == e {
+				skip = true
+			}
+		}
+		if !skip {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package basepo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultBatchSize 没有指定分段大小时每条语句包含的最大行数
+const DefaultBatchSize = 500
+
+// Chunk 批量写入中的一段，[Start, End)
+type Chunk struct {
+	Start int
+	End   int
+}
+
+// Chunks 按size把n行拆分成多段，size<=0时使用DefaultBatchSize
+func Chunks(n, size int) []Chunk {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	chunks := make([]Chunk, 0)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		chunks = append(chunks, Chunk{Start: start, End: end})
+	}
+	return chunks
+}
+
+// ChunkError 某一段写入失败
+type ChunkError struct {
+	Chunk
+	Err error
+}
+
+func (e *ChunkError) Error() string {
+	return "rows [" + strconv.Itoa(e.Start) + "," + strconv.Itoa(e.End) + "): " + e.Err.Error()
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError 批量写入中所有失败的段，其他段已经写入
+type BatchError []*ChunkError
+
+func (e BatchError) Error() string {
+	items := make([]string, 0, len(e))
+	for _, c := range e {
+		items = append(items, c.Error())
+	}
+	return "ngorm: " + strconv.Itoa(len(e)) + " chunks failed: " + strings.Join(items, "; ")
+}
+
+// DeleteOptions 按条件批量删除的选项
+type DeleteOptions struct {
+	DryRun  bool // 只返回匹配的数量，不删除
+	MaxRows int  // 匹配的数量超过MaxRows时不删除并返回ErrTooManyRows，0为不限制
+	Lookup  bool // 通过LOOKUP ON查找，条件中的属性需要有索引
+	All     bool // 条件为nil时删除全部，否则返回ErrNoCondition
+}
@@ -0,0 +1,13 @@
+package basepo
+
+// Conflict 插入的点或边已经存在时的处理方式
+type Conflict int
+
+const (
+	// ConflictOverwrite 覆盖已有的属性，和INSERT的默认行为一致
+	ConflictOverwrite Conflict = iota
+	// ConflictIgnore 使用IF NOT EXISTS，已存在时不做修改
+	ConflictIgnore
+	// ConflictFail 先检查是否存在，存在时返回ErrAlreadyExists
+	ConflictFail
+)
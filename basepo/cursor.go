@@ -0,0 +1,108 @@
+package basepo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type cursor struct {
+	Field string      `json:"f,omitempty"`
+	Value interface{} `json:"v,omitempty"`
+	Id    int64       `json:"id"`
+}
+
+// EncodeCursor 用排序字段的值和点的id生成不透明的cursor
+func EncodeCursor(field string, value interface{}, id int64) string {
+	data, _ := json.Marshal(cursor{Field: field, Value: value, Id: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// 保留整数的精度，Value转换时原样输出
+	dec.UseNumber()
+	c := &cursor{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return c, nil
+}
+
+// keyset 分页只支持一个排序字段，点的id作为第二排序字段保证顺序唯一；
+// 只按id排序时order为nil，desc是id的方向
+func (q *Query) keyset() (order *Order, desc bool, err error) {
+	if q.edge || q.lookup {
+		return nil, false, errors.New("ngorm: keyset pagination only supports MATCH on tags")
+	}
+	switch len(q.orders) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		if q.orders[0].field.kind != fieldProp {
+			return nil, q.orders[0].desc, nil
+		}
+		return &q.orders[0], q.orders[0].desc, nil
+	}
+	return nil, false, errors.New("ngorm: keyset pagination supports only one order field")
+}
+
+// KeysetNQL 编译成键集分页的MATCH语句，cursor为空时从第一页开始；忽略Offset和Limit。
+// 排序字段为NULL的点会被条件过滤掉，排序字段应该是非空的
+func (q *Query) KeysetNQL(pattern, returns, after string, size int64) (string, error) {
+	order, desc, err := q.keyset()
+	if err != nil {
+		return "", err
+	}
+	page := *q
+	page.orders = nil
+	page.offset = 0
+	page.limit = size
+	id := IdField.Asc()
+	id.desc = desc
+	if order != nil {
+		page.orders = append(page.orders, *order)
+	}
+	page.orders = append(page.orders, id)
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return "", err
+		}
+		next := IdField.Gt
+		if id.desc {
+			next = IdField.Lt
+		}
+		if order == nil {
+			if c.Field != "" {
+				return "", fmt.Errorf("%w: ordered by %s", ErrInvalidCursor, c.Field)
+			}
+			page.Where(next(c.Id))
+		} else {
+			if c.Field != order.field.name {
+				return "", fmt.Errorf("%w: ordered by %s", ErrInvalidCursor, c.Field)
+			}
+			after := order.field.Gt
+			if order.desc {
+				after = order.field.Lt
+			}
+			page.Where(Or(after(c.Value), And(order.field.Eq(c.Value), next(c.Id))))
+		}
+	}
+	return page.MatchNQL(pattern, returns), nil
+}
+
+// NextCursor 通过最后一行生成下一页的cursor，value返回字段的值
+func (q *Query) NextCursor(value func(field string) interface{}, id int64) string {
+	order, _, _ := q.keyset()
+	if order == nil {
+		return EncodeCursor("", nil, id)
+	}
+	return EncodeCursor(order.field.name, value(order.field.name), id)
+}
@@ -0,0 +1,130 @@
+package basepo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeysetNQL(t *testing.T) {
+	age, name := NewField("age"), NewField("name")
+	byAge := NewTagQuery("user").OrderBy(age.Desc())
+	byName := NewTagQuery("user").Where(age.Gt(18)).OrderBy(name.Asc())
+	byId := NewTagQuery("user")
+	byIdDesc := NewTagQuery("user").OrderBy(IdField.Desc())
+	tests := []struct {
+		name  string
+		query *Query
+		after string
+		want  string
+	}{
+		{
+			"first page",
+			byAge,
+			"",
+			"MATCH (v:user) RETURN v ORDER BY user_age DESC,user_id DESC LIMIT 2",
+		},
+		{
+			"desc",
+			byAge,
+			byAge.NextCursor(func(string) interface{} { return int64(30) }, 7),
+			"MATCH (v:user) WHERE (v.user.age < 30 OR (v.user.age == 30 AND id(v) < 7)) RETURN v ORDER BY user_age DESC,user_id DESC LIMIT 2",
+		},
+		{
+			"asc keeps the query condition",
+			byName,
+			byName.NextCursor(func(string) interface{} { return "bob" }, 3),
+			`MATCH (v:user) WHERE (v.user.age > 18 AND (v.user.name > "bob" OR (v.user.name == "bob" AND id(v) > 3))) RETURN v ORDER BY user_name,user_id LIMIT 2`,
+		},
+		{
+			"by id",
+			byId,
+			byId.NextCursor(nil, 9),
+			"MATCH (v:user) WHERE id(v) > 9 RETURN v ORDER BY user_id LIMIT 2",
+		},
+		{
+			"by id desc",
+			byIdDesc,
+			byIdDesc.NextCursor(nil, 9),
+			"MATCH (v:user) WHERE id(v) < 9 RETURN v ORDER BY user_id DESC LIMIT 2",
+		},
+		{
+			"large integers keep their precision",
+			byAge,
+			EncodeCursor("age", int64(9007199254740993), 1),
+			"MATCH (v:user) WHERE (v.user.age < 9007199254740993 OR (v.user.age == 9007199254740993 AND id(v) < 1)) RETURN v ORDER BY user_age DESC,user_id DESC LIMIT 2",
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.query.KeysetNQL("(v:user)", "v", tt.after, 2)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKeysetNQLIgnoresOffsetAndLimit(t *testing.T) {
+	q := NewTagQuery("user").Offset(10).Limit(100)
+	got, err := q.KeysetNQL("(v:user)", "v", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "MATCH (v:user) RETURN v ORDER BY user_id LIMIT 5"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if q.offset != 10 || q.limit != 100 || len(q.orders) != 0 {
+		t.Errorf("KeysetNQL modified the query: %+v", q)
+	}
+}
+
+func TestKeysetNQLErrors(t *testing.T) {
+	age, name := NewField("age"), NewField("name")
+	byAge := NewTagQuery("user").OrderBy(age.Asc())
+	tests := []struct {
+		name    string
+		query   *Query
+		after   string
+		invalid bool // 错误是否为ErrInvalidCursor
+	}{
+		{"not base64", byAge, "!!", true},
+		{"not json", byAge, "bm90IGpzb24", true},
+		{"cursor of another order", byAge, EncodeCursor("name", "bob", 1), true},
+		{"order cursor without order", NewTagQuery("user"), EncodeCursor("age", 1, 1), true},
+		{"two order fields", NewTagQuery("user").OrderBy(age.Asc(), name.Asc()), "", false},
+		{"lookup", NewTagQuery("user").UseLookup(), "", false},
+		{"edge", NewEdgeQuery("follow"), "", false},
+	}
+	for _, tt := range tests {
+		_, err := tt.query.KeysetNQL("(v:user)", "v", tt.after, 2)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		if errors.Is(err, ErrInvalidCursor) != tt.invalid {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	q := NewTagQuery("user").OrderBy(NewField("age").Asc())
+	var asked string
+	c := q.NextCursor(func(field string) interface{} {
+		asked = field
+		return 30
+	}, 7)
+	if asked != "age" {
+		t.Errorf("value asked for %q, want age", asked)
+	}
+	decoded, err := decodeCursor(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Field != "age" || decoded.Id != 7 || Value(decoded.Value) != "30" {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
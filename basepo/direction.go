@@ -0,0 +1,46 @@
+package basepo
+
+// Direction 沿着边遍历的方向
+type Direction int
+
+const (
+	// Outgoing 从起点到终点，默认方向
+	Outgoing Direction = iota
+	// Incoming 从终点到起点，REVERSELY
+	Incoming
+	// Bidirect 两个方向，BIDIRECT
+	Bidirect
+)
+
+// Over GO和FIND PATH中跟在边后面的方向
+func (d Direction) Over() string {
+	switch d {
+	case Incoming:
+		return " REVERSELY"
+	case Bidirect:
+		return " BIDIRECT"
+	}
+	return ""
+}
+
+// Subgraph GET SUBGRAPH中边前面的方向
+func (d Direction) Subgraph() string {
+	switch d {
+	case Incoming:
+		return "IN"
+	case Bidirect:
+		return "BOTH"
+	}
+	return "OUT"
+}
+
+// pattern MATCH中带方向的边
+func (d Direction) pattern(edge string) string {
+	switch d {
+	case Incoming:
+		return "<-" + edge + "-"
+	case Bidirect:
+		return "-" + edge + "-"
+	}
+	return "-" + edge + "->"
+}
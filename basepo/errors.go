@@ -0,0 +1,30 @@
+package basepo
+
+import "errors"
+
+var (
+	// ErrUnknownField 字段名不属于实体
+	ErrUnknownField = errors.New("ngorm: unknown field")
+	// ErrNotProperty 写入的字段不是属性，例如id、src、dst、rank只能作为条件
+	ErrNotProperty = errors.New("ngorm: not a property")
+	// ErrAlreadyExists 以ConflictFail插入时点或边已经存在
+	ErrAlreadyExists = errors.New("ngorm: already exists")
+	// ErrStaleObject 修改时版本字段已经被其他人修改
+	ErrStaleObject = errors.New("ngorm: stale object")
+	// ErrTooManyRows 按条件删除时匹配的数量超过了DeleteOptions.MaxRows
+	ErrTooManyRows = errors.New("ngorm: too many rows")
+	// ErrNoCondition 按条件删除时没有条件，删除全部需要设置DeleteOptions.All
+	ErrNoCondition = errors.New("ngorm: no condition")
+	// ErrNotIndexed LOOKUP ON的条件使用了没有索引的字段
+	ErrNotIndexed = errors.New("ngorm: not indexed")
+	// ErrRestricted 删除点时还存在声明了restrict策略的边
+	ErrRestricted = errors.New("ngorm: restricted by edges")
+	// ErrUnloadedField 修改的字段在投影查询时没有加载
+	ErrUnloadedField = errors.New("ngorm: unloaded field")
+	// ErrNotFound 按id加载时点不存在
+	ErrNotFound = errors.New("ngorm: not found")
+	// ErrNoRows 没有满足条件的数据，Avg、Min、Max等聚合的结果为NULL
+	ErrNoRows = errors.New("ngorm: no rows")
+	// ErrInvalidCursor 分页的cursor无法解析，或者不是按这个查询的排序字段生成的
+	ErrInvalidCursor = errors.New("ngorm: invalid cursor")
+)
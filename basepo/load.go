@@ -0,0 +1,19 @@
+package basepo
+
+// Load OneWith和ListWith的加载选项
+type Load struct {
+	Select  []Field // 只查询并绑定这些字段，其他字段记录为未加载；为空时查询全部字段
+	Preload []IEdge // 查询后批量加载的关系，例如 &UserGroup{}，实体上需要有对应的rel字段
+}
+
+// SelectNames 选择的字段名
+func (l Load) SelectNames() []string {
+	if len(l.Select) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(l.Select))
+	for _, f := range l.Select {
+		names = append(names, f.Name())
+	}
+	return names
+}
@@ -0,0 +1,16 @@
+package basepo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadSelectNames(t *testing.T) {
+	if names := (Load{}).SelectNames(); names != nil {
+		t.Errorf("empty select: got %v", names)
+	}
+	load := Load{Select: []Field{NewField("age"), IdField}}
+	if names := load.SelectNames(); !reflect.DeepEqual(names, []string{"age", "id"}) {
+		t.Errorf("got %v", names)
+	}
+}
@@ -1,6 +1,9 @@
 package basepo
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/jeek120/ngorm/util/snowflake"
 )
 
@@ -17,7 +20,8 @@ type IEdge interface {
 }
 
 type Tag struct {
-	id int64
+	id       int64
+	unloaded map[string]bool // 按字段投影查询时没有加载的字段
 }
 
 type Edge struct {
@@ -26,6 +30,9 @@ type Edge struct {
 	rank int
 }
 
+// Result 嵌入后表示多实体查询的结果结构体，ngormgen为其生成按别名绑定的Scan
+type Result struct{}
+
 func (t *Tag) GenId() int64 {
 	t.id = snowflake.Id().Generate().Int64()
 	return t.id
@@ -46,6 +53,55 @@ func (t *Tag) Id2() int64 {
 	return t.id
 }
 
+// SetUnloaded 记录没有加载的字段，覆盖之前的记录；不传时表示全部已加载
+func (t *Tag) SetUnloaded(fields ...string) {
+	if t == nil {
+		return
+	}
+	t.unloaded = nil
+	for _, f := range fields {
+		if t.unloaded == nil {
+			t.unloaded = make(map[string]bool, len(fields))
+		}
+		t.unloaded[f] = true
+	}
+}
+
+// MarkLoaded 手动给没有加载的字段赋值后标记为已加载，之后才能Update
+func (t *Tag) MarkLoaded(fields ...string) {
+	if t == nil {
+		return
+	}
+	for _, f := range fields {
+		delete(t.unloaded, f)
+	}
+}
+
+func (t *Tag) Unloaded() []string {
+	if t == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(t.unloaded))
+	for f := range t.unloaded {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CheckLoaded 修改没有加载的字段会把零值写回数据库，返回ErrUnloadedField
+func (t *Tag) CheckLoaded(fields ...string) error {
+	if t == nil {
+		return nil
+	}
+	for _, f := range fields {
+		if t.unloaded[f] {
+			return fmt.Errorf("%w: %s", ErrUnloadedField, f)
+		}
+	}
+	return nil
+}
+
 func NewEdge(src, dst int64) *Edge {
 	return &Edge{
 		src: src,
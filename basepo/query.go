@@ -0,0 +1,506 @@
+package basepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	fieldProp fieldKind = iota
+	fieldId
+	fieldSrc
+	fieldDst
+	fieldRank
+)
+
+// Field 查询中使用的属性，由ngormgen为每个实体生成，例如 UserAge
+type Field struct {
+	kind fieldKind
+	name string
+}
+
+// 点的id以及边的起点、终点和rank
+var (
+	IdField   = Field{kind: fieldId, name: "id"}
+	SrcField  = Field{kind: fieldSrc, name: "src"}
+	DstField  = Field{kind: fieldDst, name: "dst"}
+	RankField = Field{kind: fieldRank, name: "rank"}
+)
+
+func NewField(name string) Field {
+	return Field{kind: fieldProp, name: name}
+}
+
+func (f Field) Name() string {
+	return f.name
+}
+
+func (f Field) Eq(v interface{}) Cond {
+	return &compare{field: f, op: "==", value: v}
+}
+
+func (f Field) Ne(v interface{}) Cond {
+	return &compare{field: f, op: "!=", value: v}
+}
+
+func (f Field) Gt(v interface{}) Cond {
+	return &compare{field: f, op: ">", value: v}
+}
+
+func (f Field) Ge(v interface{}) Cond {
+	return &compare{field: f, op: ">=", value: v}
+}
+
+func (f Field) Lt(v interface{}) Cond {
+	return &compare{field: f, op: "<", value: v}
+}
+
+func (f Field) Le(v interface{}) Cond {
+	return &compare{field: f, op: "<=", value: v}
+}
+
+// In 可以传多个值，也可以只传一个切片，例如 UserId.In(ids)
+func (f Field) In(vs ...interface{}) Cond {
+	if len(vs) == 1 {
+		if rv := reflect.ValueOf(vs[0]); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			return &compare{field: f, op: "IN", value: vs[0]}
+		}
+	}
+	return &compare{field: f, op: "IN", value: vs}
+}
+
+func (f Field) Contains(s string) Cond {
+	return &compare{field: f, op: "CONTAINS", value: s}
+}
+
+func (f Field) StartsWith(s string) Cond {
+	return &compare{field: f, op: "STARTS WITH", value: s}
+}
+
+func (f Field) EndsWith(s string) Cond {
+	return &compare{field: f, op: "ENDS WITH", value: s}
+}
+
+func (f Field) IsNull() Cond {
+	return &isNull{field: f}
+}
+
+func (f Field) IsNotNull() Cond {
+	return &isNull{field: f, not: true}
+}
+
+func (f Field) Asc() Order {
+	return Order{field: f}
+}
+
+func (f Field) Desc() Order {
+	return Order{field: f, desc: true}
+}
+
+// Cond 查询条件，通过Field的比较方法以及And、Or、Not组合
+type Cond interface {
+	nql(prop func(Field) string) string
+}
+
+type compare struct {
+	field Field
+	op    string
+	value interface{}
+}
+
+func (c *compare) nql(prop func(Field) string) string {
+	return prop(c.field) + " " + c.op + " " + Value(c.value)
+}
+
+type isNull struct {
+	field Field
+	not   bool
+}
+
+func (c *isNull) nql(prop func(Field) string) string {
+	if c.not {
+		return prop(c.field) + " IS NOT NULL"
+	}
+	return prop(c.field) + " IS NULL"
+}
+
+type logic struct {
+	op    string
+	conds []Cond
+}
+
+func (c *logic) nql(prop func(Field) string) string {
+	items := make([]string, 0, len(c.conds))
+	for _, cond := range c.conds {
+		items = append(items, cond.nql(prop))
+	}
+	if len(items) == 1 {
+		return items[0]
+	}
+	return "(" + strings.Join(items, " "+c.op+" ") + ")"
+}
+
+type not struct {
+	cond Cond
+}
+
+func (c *not) nql(prop func(Field) string) string {
+	return "NOT (" + c.cond.nql(prop) + ")"
+}
+
+func And(conds ...Cond) Cond {
+	return &logic{op: "AND", conds: conds}
+}
+
+func Or(conds ...Cond) Cond {
+	return &logic{op: "OR", conds: conds}
+}
+
+func Not(cond Cond) Cond {
+	return &not{cond: cond}
+}
+
+// Value 把Go的值转换成nGQL的字面量，切片转换成列表，不支持的类型会panic
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'E', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'E', -1, 64)
+	case json.Number:
+		return string(v)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, Value(item))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	return reflectValue(reflect.ValueOf(v))
+}
+
+// reflectValue 处理[]int64之类的切片以及底层是基本类型的自定义类型
+func reflectValue(rv reflect.Value) string {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return Value(rv.Elem().Interface())
+	case reflect.String:
+		return strconv.Quote(rv.String())
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'E', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'E', -1, 64)
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, Value(rv.Index(i).Interface()))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	panic(fmt.Sprintf("basepo: unsupported value type %T", rv.Interface()))
+}
+
+type nullsOrder int
+
+const (
+	nullsDefault nullsOrder = iota
+	nullsFirst
+	nullsLast
+)
+
+// Order 排序，通过Field的Asc和Desc生成
+type Order struct {
+	field Field
+	desc  bool
+	nulls nullsOrder
+}
+
+// NullsFirst NULL排在最前面，通过额外返回的 <alias>_null 列排序实现
+func (o Order) NullsFirst() Order {
+	o.nulls = nullsFirst
+	return o
+}
+
+// NullsLast NULL排在最后面
+func (o Order) NullsLast() Order {
+	o.nulls = nullsLast
+	return o
+}
+
+func (o Order) Field() Field {
+	return o.field
+}
+
+// Query 查询构造器，编译成MATCH或者LOOKUP语句
+type Query struct {
+	entity string // tag或者edge的名称
+	edge   bool
+	lookup bool
+	cond   Cond
+	orders []Order
+	offset int64
+	limit  int64
+}
+
+func NewTagQuery(tag string) *Query {
+	return &Query{entity: tag}
+}
+
+func NewEdgeQuery(edge string) *Query {
+	return &Query{entity: edge, edge: true}
+}
+
+// compact 去掉nil条件
+func compact(conds []Cond) []Cond {
+	result := make([]Cond, 0, len(conds))
+	for _, c := range conds {
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Where 追加AND条件
+func (q *Query) Where(conds ...Cond) *Query {
+	conds = compact(conds)
+	if len(conds) == 0 {
+		return q
+	}
+	if q.cond != nil {
+		conds = append([]Cond{q.cond}, conds...)
+	}
+	q.cond = And(conds...)
+	return q
+}
+
+// Or 已有的条件和conds（AND连接）之间取OR
+func (q *Query) Or(conds ...Cond) *Query {
+	conds = compact(conds)
+	if len(conds) == 0 {
+		return q
+	}
+	if q.cond == nil {
+		q.cond = And(conds...)
+	} else {
+		q.cond = Or(q.cond, And(conds...))
+	}
+	return q
+}
+
+func (q *Query) OrderBy(orders ...Order) *Query {
+	q.orders = append(q.orders, orders...)
+	return q
+}
+
+func (q *Query) Offset(offset int64) *Query {
+	q.offset = offset
+	return q
+}
+
+func (q *Query) Limit(limit int64) *Query {
+	q.limit = limit
+	return q
+}
+
+// UseLookup 通过LOOKUP ON查询，条件中的属性需要有索引
+func (q *Query) UseLookup() *Query {
+	q.lookup = true
+	return q
+}
+
+func (q *Query) IsLookup() bool {
+	return q.lookup
+}
+
+// CondFields 条件中使用的字段名，LOOKUP前通过生成的CheckLookup校验是否有索引
+func (q *Query) CondFields() []string {
+	fields := make([]string, 0)
+	if q.cond != nil {
+		q.cond.nql(func(f Field) string {
+			fields = append(fields, f.name)
+			return ""
+		})
+	}
+	return fields
+}
+
+// Alias 属性在返回结果中的列名，和生成的BindRecord一致
+func (q *Query) Alias(f Field) string {
+	return q.entity + "_" + f.name
+}
+
+func (q *Query) matchProp(f Field) string {
+	switch f.kind {
+	case fieldId:
+		return "id(v)"
+	case fieldSrc:
+		return "src(e)"
+	case fieldDst:
+		return "dst(e)"
+	case fieldRank:
+		return "rank(e)"
+	}
+	if q.edge {
+		return "e." + f.name
+	}
+	return "v." + q.entity + "." + f.name
+}
+
+func (q *Query) lookupProp(f Field) string {
+	switch f.kind {
+	case fieldId:
+		return "id(vertex)"
+	case fieldSrc:
+		return "src(edge)"
+	case fieldDst:
+		return "dst(edge)"
+	case fieldRank:
+		return "rank(edge)"
+	}
+	return q.entity + "." + f.name
+}
+
+// yieldProp LOOKUP的YIELD中通过properties取属性
+func (q *Query) yieldProp(f Field) string {
+	if f.kind != fieldProp {
+		return q.lookupProp(f)
+	}
+	if q.edge {
+		return "properties(edge)." + f.name
+	}
+	return "properties(vertex)." + f.name
+}
+
+func whenProp(f Field) string {
+	switch f.kind {
+	case fieldId:
+		return "id($^)"
+	case fieldSrc:
+		return "src(edge)"
+	case fieldDst:
+		return "dst(edge)"
+	case fieldRank:
+		return "rank(edge)"
+	}
+	return f.name
+}
+
+// WhenNQL UPDATE和UPSERT语句WHEN子句中的条件，属性不需要前缀
+func WhenNQL(cond Cond) string {
+	return cond.nql(whenProp)
+}
+
+func (q *Query) orderBy(prefix string) string {
+	if len(q.orders) == 0 {
+		return ""
+	}
+	items := make([]string, 0, len(q.orders))
+	for _, o := range q.orders {
+		switch o.nulls {
+		case nullsFirst:
+			items = append(items, prefix+q.Alias(o.field)+"_null DESC")
+		case nullsLast:
+			items = append(items, prefix+q.Alias(o.field)+"_null")
+		}
+		item := prefix + q.Alias(o.field)
+		if o.desc {
+			item += " DESC"
+		}
+		items = append(items, item)
+	}
+	return " ORDER BY " + strings.Join(items, ",")
+}
+
+// nullColumns 指定了NULL顺序的排序字段需要额外返回是否为NULL的列
+func (q *Query) nullColumns(prop func(Field) string) string {
+	columns := ""
+	for _, o := range q.orders {
+		if o.nulls != nullsDefault {
+			columns += "," + prop(o.field) + " IS NULL AS " + q.Alias(o.field) + "_null"
+		}
+	}
+	return columns
+}
+
+// OrderFields 排序使用的字段名，生成的方法通过CheckFields校验
+func (q *Query) OrderFields() []string {
+	fields := make([]string, 0, len(q.orders))
+	for _, o := range q.orders {
+		fields = append(fields, o.field.name)
+	}
+	return fields
+}
+
+// OrderNQL 手写RETURN的语句使用，返回需要追加到RETURN的列以及ORDER BY子句
+func (q *Query) OrderNQL() (string, string) {
+	return q.nullColumns(q.matchProp), q.orderBy("")
+}
+
+// MatchNQL 编译成 MATCH <pattern> WHERE ... RETURN <returns> ORDER BY ... SKIP ... LIMIT ...
+func (q *Query) MatchNQL(pattern, returns string) string {
+	nql := "MATCH " + pattern
+	if q.cond != nil {
+		nql += " WHERE " + q.cond.nql(q.matchProp)
+	}
+	nql += " RETURN " + returns + q.nullColumns(q.matchProp) + q.orderBy("")
+	if q.offset > 0 {
+		nql += " SKIP " + strconv.FormatInt(q.offset, 10)
+	}
+	if q.limit > 0 {
+		nql += " LIMIT " + strconv.FormatInt(q.limit, 10)
+	}
+	return nql
+}
+
+// LookupNQL 编译成 LOOKUP ON ... WHERE ... YIELD <yields> | ORDER BY ... | LIMIT ...
+func (q *Query) LookupNQL(yields string) string {
+	nql := "LOOKUP ON " + q.entity
+	if q.cond != nil {
+		nql += " WHERE " + q.cond.nql(q.lookupProp)
+	}
+	nql += " YIELD " + yields + q.nullColumns(q.yieldProp)
+	if len(q.orders) > 0 {
+		nql += " |" + q.orderBy("$-.")
+	}
+	// 和MATCH一样，只设置了offset时也跳过前面的行
+	if q.limit > 0 || q.offset > 0 {
+		limit := q.limit
+		if limit <= 0 {
+			limit = math.MaxInt64
+		}
+		nql += " | LIMIT " + strconv.FormatInt(q.offset, 10) + "," + strconv.FormatInt(limit, 10)
+	}
+	return nql
+}
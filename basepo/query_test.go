@@ -0,0 +1,193 @@
+package basepo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type status string
+
+func TestValue(t *testing.T) {
+	name := "a"
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "NULL"},
+		{"a\"b", `"a\"b"`},
+		{true, "true"},
+		{int8(-3), "-3"},
+		{int64(9007199254740993), "9007199254740993"},
+		{1.5, "1.5E+00"},
+		{float32(0.25), "2.5E-01"},
+		{json.Number("42"), "42"},
+		{[]interface{}{1, "x"}, `[1,"x"]`},
+		{[]int64{1, 2}, "[1,2]"},
+		{[]string{"a", "b"}, `["a","b"]`},
+		{[2]int{1, 2}, "[1,2]"},
+		{status("done"), `"done"`},
+		{&name, `"a"`},
+		{(*string)(nil), "NULL"},
+	}
+	for _, tt := range tests {
+		if got := Value(tt.value); got != tt.want {
+			t.Errorf("Value(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValueUnsupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Value(struct) did not panic")
+		}
+	}()
+	Value(struct{}{})
+}
+
+func TestIn(t *testing.T) {
+	age := NewField("age")
+	tests := []struct {
+		cond Cond
+		want string
+	}{
+		{age.In(1, 2), "MATCH (v:user) WHERE v.user.age IN [1,2] RETURN v"},
+		{age.In([]int64{1, 2}), "MATCH (v:user) WHERE v.user.age IN [1,2] RETURN v"},
+		{age.In([]interface{}{1, 2}), "MATCH (v:user) WHERE v.user.age IN [1,2] RETURN v"},
+		{NewField("name").In("a"), `MATCH (v:user) WHERE v.user.name IN ["a"] RETURN v`},
+	}
+	for _, tt := range tests {
+		if got := NewTagQuery("user").Where(tt.cond).MatchNQL("(v:user)", "v"); got != tt.want {
+			t.Errorf("got  %s\nwant %s", got, tt.want)
+		}
+	}
+}
+
+func TestMatchNQL(t *testing.T) {
+	age, name := NewField("age"), NewField("name")
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+	}{
+		{
+			"empty",
+			NewTagQuery("user"),
+			"MATCH (v:user) RETURN v",
+		},
+		{
+			"where order skip limit",
+			NewTagQuery("user").Where(age.Gt(18), name.StartsWith("a")).OrderBy(age.Desc()).Offset(5).Limit(10),
+			`MATCH (v:user) WHERE (v.user.age > 18 AND v.user.name STARTS WITH "a") RETURN v ORDER BY user_age DESC SKIP 5 LIMIT 10`,
+		},
+		{
+			"or not",
+			NewTagQuery("user").Where(age.Ge(1)).Or(Not(name.IsNull())),
+			"MATCH (v:user) WHERE (v.user.age >= 1 OR NOT (v.user.name IS NULL)) RETURN v",
+		},
+		{
+			"nil conditions are ignored",
+			NewTagQuery("user").Where(nil).Or(nil),
+			"MATCH (v:user) RETURN v",
+		},
+		{
+			"id",
+			NewTagQuery("user").Where(IdField.Eq(3)),
+			"MATCH (v:user) WHERE id(v) == 3 RETURN v",
+		},
+		{
+			"nulls first and last",
+			NewTagQuery("user").OrderBy(name.Asc().NullsFirst(), age.Desc().NullsLast()),
+			"MATCH (v:user) RETURN v,v.user.name IS NULL AS user_name_null,v.user.age IS NULL AS user_age_null ORDER BY user_name_null DESC,user_name,user_age_null,user_age DESC",
+		},
+		{
+			"offset without limit",
+			NewTagQuery("user").Offset(3),
+			"MATCH (v:user) RETURN v SKIP 3",
+		},
+		{
+			"edge",
+			NewEdgeQuery("follow").Where(SrcField.Eq(1), RankField.Ne(0), NewField("degree").Le(2.5)),
+			"MATCH ()-[e:follow]->() WHERE (src(e) == 1 AND rank(e) != 0 AND e.degree <= 2.5E+00) RETURN e",
+		},
+	}
+	for _, tt := range tests {
+		pattern, returns := "(v:user)", "v"
+		if tt.query.edge {
+			pattern, returns = "()-[e:follow]->()", "e"
+		}
+		if got := tt.query.MatchNQL(pattern, returns); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookupNQL(t *testing.T) {
+	age, name := NewField("age"), NewField("name")
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+	}{
+		{
+			"empty",
+			NewTagQuery("user"),
+			"LOOKUP ON user YIELD id(vertex) AS user_id",
+		},
+		{
+			"where order offset limit",
+			NewTagQuery("user").Where(age.Gt(18), name.StartsWith("a")).OrderBy(age.Desc()).Offset(5).Limit(10),
+			`LOOKUP ON user WHERE (user.age > 18 AND user.name STARTS WITH "a") YIELD id(vertex) AS user_id | ORDER BY $-.user_age DESC | LIMIT 5,10`,
+		},
+		{
+			"limit without offset",
+			NewTagQuery("user").Limit(10),
+			"LOOKUP ON user YIELD id(vertex) AS user_id | LIMIT 0,10",
+		},
+		{
+			"offset without limit",
+			NewTagQuery("user").Offset(3),
+			"LOOKUP ON user YIELD id(vertex) AS user_id | LIMIT 3,9223372036854775807",
+		},
+		{
+			"nulls first",
+			NewTagQuery("user").OrderBy(name.Asc().NullsFirst()),
+			"LOOKUP ON user YIELD id(vertex) AS user_id,properties(vertex).name IS NULL AS user_name_null | ORDER BY $-.user_name_null DESC,$-.user_name",
+		},
+		{
+			"edge",
+			NewEdgeQuery("follow").Where(SrcField.Eq(1), NewField("degree").Lt(3)),
+			"LOOKUP ON follow WHERE (src(edge) == 1 AND follow.degree < 3) YIELD id(vertex) AS user_id",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.query.LookupNQL("id(vertex) AS user_id"); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWhenNQL(t *testing.T) {
+	got := WhenNQL(And(NewField("status").Eq("pending"), IdField.Gt(1)))
+	want := `(status == "pending" AND id($^) > 1)`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestCondFields(t *testing.T) {
+	q := NewTagQuery("user").Where(NewField("name").Eq("a"), Or(IdField.Gt(1), Not(NewField("age").IsNull())))
+	got := q.CondFields()
+	want := []string{"name", "id", "age"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if fields := NewTagQuery("user").CondFields(); len(fields) != 0 {
+		t.Errorf("empty query has fields %v", fields)
+	}
+}
@@ -0,0 +1,67 @@
+package basepo
+
+// 边上声明的删除策略，ngorm:"onDelete=cascade"
+const (
+	// OnDeleteDetach 删除点时删除这种边，默认策略
+	OnDeleteDetach = "detach"
+	// OnDeleteCascade 删除起点时删除这种边以及边的终点，删除终点时和detach一样
+	OnDeleteCascade = "cascade"
+	// OnDeleteRestrict 还有这种边时拒绝删除点，返回ErrRestricted
+	OnDeleteRestrict = "restrict"
+)
+
+// RemoveReport 按删除策略删除点时实际删除的内容
+type RemoveReport struct {
+	Vertices []int64        // 删除的点，包括级联删除的点
+	Edges    map[string]int // 每种边删除的数量
+}
+
+func NewRemoveReport() *RemoveReport {
+	return &RemoveReport{Edges: make(map[string]int)}
+}
+
+// Merge 合并级联删除的结果
+func (r *RemoveReport) Merge(other *RemoveReport) {
+	if other == nil {
+		return
+	}
+	r.Vertices = append(r.Vertices, other.Vertices...)
+	for name, n := range other.Edges {
+		r.Edges[name] += n
+	}
+}
+
+// DeletePlan 级联删除时先收集所有要删除的点并检查restrict，全部通过后才开始删除
+type DeletePlan struct {
+	visited map[int64]bool
+	steps   []func(*RemoveReport) error
+}
+
+func NewDeletePlan() *DeletePlan {
+	return &DeletePlan{visited: make(map[int64]bool)}
+}
+
+// Visit 第一次访问点时返回true，级联成环或者汇合到同一个点时只处理一次
+func (p *DeletePlan) Visit(id int64) bool {
+	if p.visited[id] {
+		return false
+	}
+	p.visited[id] = true
+	return true
+}
+
+// Add 添加删除一个点的步骤
+func (p *DeletePlan) Add(step func(*RemoveReport) error) {
+	p.steps = append(p.steps, step)
+}
+
+// Run 按添加的顺序删除，出错时返回已经删除的内容
+func (p *DeletePlan) Run() (*RemoveReport, error) {
+	report := NewRemoveReport()
+	for _, step := range p.steps {
+		if err := step(report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
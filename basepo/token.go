@@ -0,0 +1,15 @@
+package basepo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewToken 条件修改时写入token字段的随机值，YIELD出的值等于它时说明这次修改生效了
+func NewToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
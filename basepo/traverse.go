@@ -0,0 +1,103 @@
+package basepo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Traversal 沿着边的变长遍历，编译成 MATCH p = (a:x)-[:e*1..3]->(b:y)，方向通过Direction指定；
+// 由ngormgen生成的 UserTraverse 创建，生成的 Traverse 方法按VID去重后绑定终点实体
+type Traversal struct {
+	from     string
+	ids      []int64
+	edges    []string
+	dir      Direction
+	min, max int
+	conds    []Cond
+	distinct bool
+	depth    bool
+}
+
+func NewTraversal(from string, ids ...int64) *Traversal {
+	return &Traversal{from: from, ids: ids, min: 1, max: 1}
+}
+
+// Over 经过的边，多种边之间是或的关系
+func (t *Traversal) Over(edges ...IEdge) *Traversal {
+	for _, e := range edges {
+		t.edges = append(t.edges, e.EdgeName())
+	}
+	return t
+}
+
+// Direction 遍历的方向，默认Outgoing
+func (t *Traversal) Direction(dir Direction) *Traversal {
+	t.dir = dir
+	return t
+}
+
+// Steps 经过的边数，包括min和max
+func (t *Traversal) Steps(min, max int) *Traversal {
+	t.min, t.max = min, max
+	return t
+}
+
+// Where 终点需要满足的条件
+func (t *Traversal) Where(conds ...Cond) *Traversal {
+	t.conds = append(t.conds, compact(conds)...)
+	return t
+}
+
+// Distinct 在数据库中去重，减少返回的行数
+func (t *Traversal) Distinct() *Traversal {
+	t.distinct = true
+	return t
+}
+
+// WithDepth 同时返回到达每个终点的最短步数
+func (t *Traversal) WithDepth() *Traversal {
+	t.depth = true
+	return t
+}
+
+func (t *Traversal) HasDepth() bool {
+	return t.depth
+}
+
+// MatchNQL 编译成MATCH语句，终点为target，返回列 v 以及 WithDepth 时的 depth
+func (t *Traversal) MatchNQL(target string) string {
+	prop := func(f Field) string {
+		if f.kind == fieldId {
+			return "id(b)"
+		}
+		return "b." + target + "." + f.name
+	}
+	// 没有Over时沿着任意类型的边，不能写成 [:*1..3]
+	types := ""
+	if len(t.edges) > 0 {
+		types = ":" + strings.Join(t.edges, "|")
+	}
+	edge := "[" + types + "*" + strconv.Itoa(t.min) + ".." + strconv.Itoa(t.max) + "]"
+	nql := "MATCH p = (a:" + t.from + ")" + t.dir.pattern(edge) + "(b:" + target + ")"
+	where := make([]string, 0, len(t.conds)+1)
+	if len(t.ids) > 0 {
+		ids := make([]string, 0, len(t.ids))
+		for _, id := range t.ids {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		where = append(where, "id(a) IN ["+strings.Join(ids, ",")+"]")
+	}
+	if len(t.conds) > 0 {
+		where = append(where, And(t.conds...).nql(prop))
+	}
+	if len(where) > 0 {
+		nql += " WHERE " + strings.Join(where, " AND ")
+	}
+	if t.depth {
+		return nql + " RETURN b AS v, min(length(p)) AS depth"
+	}
+	if t.distinct {
+		return nql + " RETURN DISTINCT b AS v"
+	}
+	return nql + " RETURN b AS v"
+}
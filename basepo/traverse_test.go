@@ -0,0 +1,53 @@
+package basepo
+
+import "testing"
+
+type knows struct {
+	Edge
+}
+
+func (knows) EdgeName() string {
+	return "knows"
+}
+
+type likes struct {
+	Edge
+}
+
+func (likes) EdgeName() string {
+	return "likes"
+}
+
+func TestTraversalMatchNQL(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *Traversal
+		want string
+	}{
+		{
+			"any edge",
+			NewTraversal("person", 1).Steps(1, 3),
+			"MATCH p = (a:person)-[*1..3]->(b:person) WHERE id(a) IN [1] RETURN b AS v",
+		},
+		{
+			"over",
+			NewTraversal("person", 1, 2).Over(&knows{}, &likes{}).Steps(2, 2),
+			"MATCH p = (a:person)-[:knows|likes*2..2]->(b:person) WHERE id(a) IN [1,2] RETURN b AS v",
+		},
+		{
+			"incoming distinct",
+			NewTraversal("person").Over(&knows{}).Direction(Incoming).Distinct(),
+			"MATCH p = (a:person)<-[:knows*1..1]-(b:person) RETURN DISTINCT b AS v",
+		},
+		{
+			"bidirect where depth",
+			NewTraversal("person", 1).Over(&knows{}).Direction(Bidirect).Where(NewField("name").StartsWith("a"), IdField.Ne(1)).WithDepth(),
+			`MATCH p = (a:person)-[:knows*1..1]-(b:person) WHERE id(a) IN [1] AND (b.person.name STARTS WITH "a" AND id(b) != 1) RETURN b AS v, min(length(p)) AS depth`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.t.MatchNQL("person"); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import "strings"
+
+// isNumeric 可以做Sum和Avg的字段
+func (f *Field) isNumeric() bool {
+	switch f.typeStr {
+	case "int", "int64", "int32", "int16", "int8", "float64", "float32":
+		return true
+	}
+	return false
+}
+
+func (f *Field) isFloat() bool {
+	return strings.HasPrefix(f.typeStr, "float")
+}
+
+// funcCount 统计满足条件的点或边的数量，条件和Find一样通过basepo.Cond组合
+func (g *Generator) funcCount(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Count(session *nebula_go.Session, cond basepo.Cond) (int64, error) {`)
+	g.Printlnf(`	val, err := aggregateValue(session, ` + s.name + `Query().Where(cond).AggregateNQL("` + s.matchPattern() + `", "count", nil, nil))`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return 0, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return val.AsInt()`)
+	g.Printlnf(`}`)
+}
+
+// funcExists 点按id、边按起点终点和rank判断是否存在
+func (g *Generator) funcExists(s *Struct) {
+	var params, key, yield string
+	if s.isTag {
+		params = `id int64`
+		key = `strconv.FormatInt(id, 10)`
+		yield = `YIELD id(vertex)`
+	} else if s.isEdge {
+		params = `src, dst int64, rank int`
+		key = `strconv.FormatInt(src, 10) + "->" + strconv.FormatInt(dst, 10) + "@" + strconv.Itoa(rank)`
+		yield = `YIELD src(edge)`
+	} else {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Exists(session *nebula_go.Session, ` + params + `) (bool, error) {`)
+	g.Printlnf(`	nql := "FETCH PROP ON ` + s.nickname + ` " + ` + key + ` + " ` + yield + `"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return false, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return false, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return result.GetRowSize() > 0, nil`)
+	g.Printlnf(`}`)
+}
+
+// funcAggregates 为每个数值字段生成 SumAge、AvgAge、MinAge、MaxAge，版本字段除外。
+// 整数的Sum返回int64，浮点数返回float64；没有数据时Avg、Min、Max返回basepo.ErrNoRows
+func (g *Generator) funcAggregates(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	for _, f := range s.fields {
+		if !f.isNumeric() || f.isVersion {
+			continue
+		}
+		method := strings.TrimPrefix(s.exportName(&f), s.name)
+		sumType := "int64"
+		if f.isFloat() {
+			sumType = "float64"
+		}
+		g.funcAggregate(s, &f, "Sum"+method, "sum", sumType, "")
+		g.funcAggregate(s, &f, "Avg"+method, "avg", "float64", "")
+		conv := ""
+		if f.typeStr != sumType {
+			conv = f.typeStr
+		}
+		g.funcAggregate(s, &f, "Min"+method, "min", f.typeStr, conv)
+		g.funcAggregate(s, &f, "Max"+method, "max", f.typeStr, conv)
+	}
+}
+
+func (g *Generator) funcAggregate(s *Struct, f *Field, method, fn, typ, conv string) {
+	as := "AsInt()"
+	if typ == "float64" || f.isFloat() {
+		as = "AsFloat()"
+	}
+	g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session, cond basepo.Cond) (` + typ + `, error) {`)
+	g.Printlnf(`	val, err := aggregateValue(session, ` + s.name + `Query().Where(cond).AggregateNQL("` + s.matchPattern() + `", "` + fn + `", &` + s.exportName(f) + `, nil))`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return 0, err`)
+	g.Printlnf(`	}`)
+	if conv == "" {
+		g.Printlnf(`	return val.` + as)
+	} else {
+		g.Printlnf(`	v, err := val.` + as)
+		g.Printlnf(`	return ` + conv + `(v), err`)
+	}
+	g.Printlnf(`}`)
+}
+
+// funcGroupBy 为每个字段生成 GroupByName，按字段的值统计数量，值为NULL的不统计；版本和token字段除外
+func (g *Generator) funcGroupBy(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	for _, f := range s.fields {
+		if f.isVersion || f.isToken {
+			continue
+		}
+		method := "GroupBy" + strings.TrimPrefix(s.exportName(&f), s.name)
+		g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session, cond basepo.Cond) (map[` + f.typeStr + `]int64, error) {`)
+		g.Printlnf(`	nql := ` + s.name + `Query().Where(cond).AggregateNQL("` + s.matchPattern() + `", "count", nil, &` + s.exportName(&f) + `)`)
+		g.Printlnf(`	result, err := session.Execute(nql)`)
+		g.Printlnf(`	if err != nil {`)
+		g.Printlnf(`		return nil, err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+		g.Printlnf(`		return nil, err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	groups := make(map[` + f.typeStr + `]int64, result.GetRowSize())`)
+		g.Printlnf(`	for i := 0; i < result.GetRowSize(); i++ {`)
+		g.Printlnf(`		record, err := result.GetRowValuesByIndex(i)`)
+		g.Printlnf(`		if err != nil {`)
+		g.Printlnf(`			return nil, err`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		key, err := record.GetValueByColName("key")`)
+		g.Printlnf(`		if err != nil {`)
+		g.Printlnf(`			return nil, err`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		if key.IsNull() {`)
+		g.Printlnf(`			continue`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		k, err := key.` + f.asMethod())
+		g.Printlnf(`		if err != nil {`)
+		g.Printlnf(`			return nil, err`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		val, err := record.GetValueByColName("value")`)
+		g.Printlnf(`		if err != nil {`)
+		g.Printlnf(`			return nil, err`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		n, err := val.AsInt()`)
+		g.Printlnf(`		if err != nil {`)
+		g.Printlnf(`			return nil, err`)
+		g.Printlnf(`		}`)
+		key := "k"
+		switch f.typeStr {
+		case "int", "int32", "int16", "int8", "float32":
+			key = f.typeStr + "(k)"
+		}
+		g.Printlnf(`		groups[` + key + `] += n`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	return groups, nil`)
+		g.Printlnf(`}`)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// Part 组合实体中嵌入的Tag结构体
+type Part struct {
+	name string
+	ptr  bool
+}
+
+// checkComposites 只嵌入Tag结构体的结构体是组合实体，例如
+//
+//	type Staff struct {
+//		*User
+//		*Employee
+//	}
+//
+// 所有Tag共用一个VID，插入和查询时一次处理全部Tag
+func (g *Generator) checkComposites() {
+	for i := range g.Structs {
+		s := &g.Structs[i]
+		if s.isTag || s.isEdge || s.isResult || len(s.parts) == 0 {
+			continue
+		}
+		composite := true
+		for _, p := range s.parts {
+			if t := g.findStruct(p.name); t == nil || !t.isTag {
+				composite = false
+			}
+		}
+		if !composite {
+			continue
+		}
+		if len(s.fields) > 0 {
+			log.Fatalf("composite %s: field %s does not belong to any tag", s.name, s.fields[0].name)
+		}
+		s.composite = true
+	}
+}
+
+// funcComposite 组合实体的Id、SetId、Insert和Fetch，单个Tag的修改通过嵌入的结构体，例如 m.User.Update
+func (g *Generator) funcComposite(s *Struct) {
+	tags := make([]*Struct, 0, len(s.parts))
+	for _, p := range s.parts {
+		tags = append(tags, g.findStruct(p.name))
+	}
+
+	g.Printlnf(`func (m *` + s.name + `) initTags() {`)
+	for i, p := range s.parts {
+		v := "m." + p.name
+		if p.ptr {
+			g.Printlnf(`	if ` + v + ` == nil {`)
+			g.Printlnf(`		` + v + ` = &` + p.name + `{}`)
+			g.Printlnf(`	}`)
+		}
+		g.initTagVar(tags[i], v)
+	}
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) Id() int64 {`)
+	g.Printlnf(`	m.initTags()`)
+	g.Printlnf(`	return m.` + s.parts[0].name + `.Id()`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) SetId(id int64) {`)
+	g.Printlnf(`	m.initTags()`)
+	for _, p := range s.parts {
+		g.Printlnf(`	m.` + p.name + `.SetId(id)`)
+	}
+	g.Printlnf(`}`)
+
+	names := make([]string, 0, len(tags))
+	values := make([]string, 0, len(tags))
+	for i, t := range tags {
+		v := "m." + s.parts[i].name
+		names = append(names, `" + `+v+`.TagName() + "(" + `+v+`.NqlNames(`+v+`.AllFields()...) + ")`)
+		if len(t.fields) > 0 {
+			values = append(values, v+`.NqlValues(`+v+`.AllFields()...)`)
+		}
+	}
+	g.Printlnf(`func (m *` + s.name + `) Insert(session *nebula_go.Session) error {`)
+	g.Printlnf(`	m.initTags()`)
+	g.Printlnf(`	m.SetId(m.` + s.parts[0].name + `.Id2())`)
+	g.Printlnf(`	nql := "INSERT VERTEX ` + strings.Join(names, ",") + ` VALUES " + strconv.FormatInt(m.Id(), 10) + ":(" +`)
+	if len(values) > 0 {
+		g.Printlnf(`		strings.Join([]string{` + strings.Join(values, ", ") + `}, ",") +`)
+	}
+	g.Printlnf(`		")"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return resultError(nql, result)`)
+	g.Printlnf(`}`)
+
+	nicknames := make([]string, 0, len(tags))
+	for _, t := range tags {
+		nicknames = append(nicknames, t.nickname)
+	}
+	g.Printlnf(`func (m *` + s.name + `) Fetch(session *nebula_go.Session) error {`)
+	g.Printlnf(`	nql := "FETCH PROP ON ` + strings.Join(nicknames, ",") + ` " + strconv.FormatInt(m.Id(), 10) + " YIELD vertex AS v"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if result.GetRowSize() == 0 {`)
+	g.Printlnf(`		return fmt.Errorf("%%w: ` + s.nickname + ` %%d", basepo.ErrNotFound, m.Id())`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	v := result.GetRows()[0].Values[0].GetVVal()`)
+	for _, p := range s.parts {
+		g.Printlnf(`	m.` + p.name + `.BindVertex(v)`)
+	}
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
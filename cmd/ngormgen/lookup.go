@@ -0,0 +1,144 @@
+package main
+
+import (
+	"go/token"
+	"log"
+	"strings"
+)
+
+// paramName 字段作为生成方法参数时的名字，避开关键字和方法中已有的变量
+func paramName(f *Field) string {
+	switch f.nickname {
+	case "session", "m", "ms", "err":
+		return f.nickname + "Value"
+	}
+	if token.IsKeyword(f.nickname) {
+		return f.nickname + "Value"
+	}
+	return f.nickname
+}
+
+// indexFields 解析idx标签中的索引字段，例如 idx:"name(10),age" -> name、age
+func (s *Struct) indexFields(f *Field) []*Field {
+	def := f.otherIndexFields
+	if def == "" {
+		def = f.nickname
+	}
+	fields := make([]*Field, 0)
+	for _, item := range strings.Split(def, ",") {
+		item = strings.TrimSpace(item)
+		name := item
+		if i := strings.Index(item, "("); i >= 0 {
+			name = item[:i]
+		}
+		var field *Field
+		for i := range s.fields {
+			if s.fields[i].nickname == name {
+				field = &s.fields[i]
+			}
+		}
+		if field == nil {
+			log.Fatalf("%s.%s: index field %s is not a property of %s, LOOKUP ON can not use it", s.name, f.name, name, s.name)
+		}
+		if field.typeStr == "string" && name == item {
+			log.Fatalf("%s.%s: string field %s in index needs a length, e.g. %s(10)", s.name, f.name, name, name)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// indexedFields 出现在任意一个索引中的字段
+func (s *Struct) indexedFields() []*Field {
+	fields := make([]*Field, 0)
+	exist := make(map[string]bool)
+	for i := range s.fields {
+		if !s.fields[i].isIndex {
+			continue
+		}
+		for _, f := range s.indexFields(&s.fields[i]) {
+			if !exist[f.nickname] {
+				exist[f.nickname] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// funcCheckLookup 生成 CheckLookup，Find和DeleteWhere通过LOOKUP ON查询前校验条件中的字段都有索引，
+// id、src、dst、rank以及没有idx标签的字段返回 basepo.ErrNotIndexed
+func (g *Generator) funcCheckLookup(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	indexed := s.indexedFields()
+	g.Printlnf(`func (m *` + s.name + `) CheckLookup(q *basepo.Query) error {`)
+	if len(indexed) == 0 {
+		g.Printlnf(`	return fmt.Errorf("%%w: ` + s.nickname + ` has no idx field, LOOKUP ON needs an index", basepo.ErrNotIndexed)`)
+		g.Printlnf(`}`)
+		return
+	}
+	names := make([]string, 0, len(indexed))
+	for _, f := range indexed {
+		names = append(names, `"`+f.nickname+`"`)
+	}
+	g.Printlnf(`	for _, f := range q.CondFields() {`)
+	g.Printlnf(`		switch f {`)
+	g.Printlnf(`		case ` + strings.Join(names, ", ") + `:`)
+	g.Printlnf(`		default:`)
+	g.Printlnf(`			return fmt.Errorf("%%w: ` + s.nickname + `.%%s is not in any idx, LOOKUP ON can not use it", basepo.ErrNotIndexed, f)`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcLookupBy 为每个索引生成 LookupBy<Field>，组合索引的每个前缀都会生成一个方法。
+// LOOKUP ON 只能用在索引的前缀上，不在索引中的字段由生成的CheckLookup在查询时拒绝
+func (g *Generator) funcLookupBy(s *Struct) {
+	if s.isEdge {
+		// 只提示声明了idx却生成不了方法的边，没有声明idx的字段通过CheckLookup在运行时拒绝
+		var names []string
+		for _, f := range s.fields {
+			if f.isIndex {
+				names = append(names, f.name)
+			}
+		}
+		if len(names) > 0 {
+			log.Printf("%s: Create does not create edge indexes, idx on %s does not generate LookupBy", s.name, strings.Join(names, ", "))
+		}
+		return
+	}
+	if !s.isTag {
+		return
+	}
+	generated := make(map[string]bool)
+	for _, f := range s.fields {
+		if !f.isIndex {
+			continue
+		}
+		index := s.indexFields(&f)
+		for n := 1; n <= len(index); n++ {
+			prefix := index[:n]
+			method := "LookupBy"
+			params := make([]string, 0, n)
+			conds := make([]string, 0, n)
+			for _, field := range prefix {
+				name := strings.TrimPrefix(s.exportName(field), s.name)
+				method += name
+				params = append(params, paramName(field)+" "+field.typeStr)
+				conds = append(conds, s.exportName(field)+".Eq("+paramName(field)+")")
+			}
+			if generated[method] {
+				continue
+			}
+			generated[method] = true
+			g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session, ` + strings.Join(params, ", ") + `) (` + s.name + `List, error) {`)
+			g.Printlnf(`	var ms ` + s.name + `List`)
+			g.Printlnf(`	err := m.Find(session, &ms, ` + s.name + `Query().Where(` + strings.Join(conds, ", ") + `).UseLookup())`)
+			g.Printlnf(`	return ms, err`)
+			g.Printlnf(`}`)
+		}
+	}
+}
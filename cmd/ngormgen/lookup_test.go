@@ -0,0 +1,20 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 没有声明idx的字段不需要提示，只有生成不了方法的idx才输出
+func TestLookupByQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	generateDir(t, filepath.Join("testdata", "fixture"))
+	if buf.Len() > 0 {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 const POTYPE_TAG = "Tag"
 const POTYPE_EDGE = "Edge"
+const POTYPE_RESULT = "Result"
 
 var IDFIELD = &Field{
 	name:     "Id",
@@ -26,6 +27,13 @@ var IDFIELD = &Field{
 	comment:  "",
 }
 
+// 边的起点、终点和rank，只用于查询条件
+var (
+	SRCFIELD  = &Field{name: "Src", nickname: "src", typeStr: "int64"}
+	DSTFIELD  = &Field{name: "Dst", nickname: "dst", typeStr: "int64"}
+	RANKFIELD = &Field{name: "Rank", nickname: "rank", typeStr: "int"}
+)
+
 var (
 	typeNames   = flag.String("type", "", "comma-separated list of type names; must be set")
 	output      = flag.String("output", "", "output file name; default srcdir/<type>_string.go")
@@ -81,18 +89,7 @@ func main() {
 	g.parsePackage(args, tags)
 
 	// Print the header and package clause.
-	g.Printf("// Code generated by \"ngormgen %s\"; DO NOT EDIT.\n", strings.Join(os.Args[1:], " "))
-	g.Printf("\n")
-	g.Printf("package %s", g.pkg.name)
-	g.Printf("\n")
-	g.Printf(`import (`) // Used by all methods.
-	// g.Printlnf(`	"github.com/jeek120/ngorm/util"`)
-	g.Printlnf(`	"strconv"`)
-	g.Printlnf(`	nebula_go "github.com/vesoft-inc/nebula-go/v3"`)
-	g.Printlnf(`	"github.com/vesoft-inc/nebula-go/v3/nebula"`)
-	g.Printlnf(`		"strings"`)
-	g.Printlnf(`		"fmt"`)
-	g.Printlnf(`)`)
+	g.header(os.Args[1:])
 
 	// Run generate for each type.
 	var types []string
@@ -116,6 +113,23 @@ func main() {
 	}
 }
 
+// header 输出文件头、package以及生成的代码用到的import
+func (g *Generator) header(args []string) {
+	g.Printf("// Code generated by \"ngormgen %s\"; DO NOT EDIT.\n", strings.Join(args, " "))
+	g.Printf("\n")
+	g.Printf("package %s", g.pkg.name)
+	g.Printf("\n")
+	g.Printf(`import (`) // Used by all methods.
+	// g.Printlnf(`	"github.com/jeek120/ngorm/util"`)
+	g.Printlnf(`	"strconv"`)
+	g.Printlnf(`	nebula_go "github.com/vesoft-inc/nebula-go/v3"`)
+	g.Printlnf(`	"github.com/vesoft-inc/nebula-go/v3/nebula"`)
+	g.Printlnf(`	"github.com/jeek120/ngorm/basepo"`)
+	g.Printlnf(`		"strings"`)
+	g.Printlnf(`		"fmt"`)
+	g.Printlnf(`)`)
+}
+
 // isDirectory reports whether the named file is a directory.
 func isDirectory(name string) bool {
 	info, err := os.Stat(name)
@@ -157,11 +171,21 @@ type File struct {
 
 type Struct struct {
 	// These fields are reset for each type being generated.
-	name     string // Name of the constant type.
-	nickname string
-	fields   []Field // Accumulator for constant fields of that type.
-	isTag    bool
-	isEdge   bool
+	name      string // Name of the constant type.
+	nickname  string
+	fields    []Field // Accumulator for constant fields of that type.
+	isTag     bool
+	isEdge    bool
+	embedPtr  bool   // 以指针方式嵌入basepo.Tag/basepo.Edge
+	from      string // 边的起点实体，ngorm:"from=User"
+	to        string // 边的终点实体，ngorm:"to=Group"
+	onDelete  string // 删除点时对这种边的处理，ngorm:"onDelete=cascade"
+	rels      []Relation
+	parts     []Part // 组合实体中嵌入的Tag结构体
+	composite bool   // 由多个Tag组成，共用一个VID
+	isResult  bool   // 嵌入basepo.Result的查询结果结构体
+	columns   []Column
+	members   []string // 结构体里声明的所有字段名，包括嵌入的类型名
 }
 
 type Package struct {
@@ -237,7 +261,18 @@ func (g *Generator) generate(dir string, allowTypeNames []string) {
 	}
 
 	g.checkResultSet()
+	g.pathHelpers()
+	g.checkRelations()
+	g.checkComposites()
 	for _, s := range g.Structs {
+		if s.composite {
+			g.funcComposite(&s)
+			continue
+		}
+		if s.isResult {
+			g.funcScan(&s)
+			continue
+		}
 		g.funcAllFields(&s)
 		g.funcAllFieldsWithId(&s)
 		g.funcTagName(&s)
@@ -254,10 +289,14 @@ func (g *Generator) generate(dir string, allowTypeNames []string) {
 		// 插入
 		g.funcInsertTag(&s)
 		g.funcInsertEdge(&s)
+		g.funcInsertBatch(&s)
 
 		// 修改
 		g.funcUpdateTag(&s)
 		g.funcUpdateEdge(&s)
+		g.funcUpsert(&s)
+		g.funcUpdateWhen(&s)
+		g.funcUpdateExpr(&s)
 
 		// 查询
 		g.funcBindRecord(&s)
@@ -268,10 +307,39 @@ func (g *Generator) generate(dir string, allowTypeNames []string) {
 		g.funcBindOne(&s)
 		g.funcOne(&s)
 		g.funcList(&s)
+		g.funcBindEdge(&s)
+		g.funcBindEdges(&s)
+		g.funcOneEdge(&s)
+		g.funcListEdge(&s)
+		g.funcFetchEdge(&s)
+		g.funcFieldVars(&s)
+		g.funcFieldsStruct(&s)
+		g.funcCheckFields(&s)
+		g.funcCheckProps(&s)
+		g.funcQuery(&s)
 
 		// 删除
 		g.funcRemoveTag(&s)
 		g.funcRemoveEdge(&s)
+		g.funcDeleteWhere(&s)
+		g.funcDelete(&s)
+		g.funcCount(&s)
+		g.funcExists(&s)
+		g.funcAggregates(&s)
+		g.funcGroupBy(&s)
+		g.funcPage(&s)
+		g.funcIter(&s)
+		g.funcGet(&s)
+		g.funcGetMany(&s)
+		g.funcLookupBy(&s)
+		g.funcCheckLookup(&s)
+		g.funcPath(&s)
+		g.funcTraverse(&s)
+		g.funcDirections(&s)
+
+		// 关系
+		g.funcNeighbors(&s)
+		g.funcPreload(&s)
 	}
 	g.Create()
 }
@@ -302,6 +370,34 @@ type Field struct {
 	comment          string
 	isIndex          bool
 	otherIndexFields string
+	isVersion        bool // 乐观锁的版本字段，ngorm:"version"
+	isToken          bool // 条件修改写入的随机值，ngorm:"token"
+}
+
+// versionField 乐观锁的版本字段，没有时返回nil
+func (s *Struct) versionField() *Field {
+	for i := range s.fields {
+		if s.fields[i].isVersion {
+			if s.fields[i].typeStr != "int64" {
+				log.Fatalf("%s.%s: version field must be int64", s.name, s.fields[i].name)
+			}
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
+
+// tokenField UpdateWhen判断条件是否满足的字段，没有时返回nil
+func (s *Struct) tokenField() *Field {
+	for i := range s.fields {
+		if s.fields[i].isToken {
+			if s.fields[i].typeStr != "string" {
+				log.Fatalf("%s.%s: token field must be string", s.name, s.fields[i].name)
+			}
+			return &s.fields[i]
+		}
+	}
+	return nil
 }
 
 func (v *Field) String() string {
@@ -344,32 +440,45 @@ func (f *File) genStruct(node ast.Node) bool {
 			}
 			stru.fields = make([]Field, 0)
 			for _, field := range st.Fields.List {
-
+				stru.members = append(stru.members, memberNames(field)...)
+				if marker, ptr := basepoEmbed(field); marker != "" {
+					switch marker {
+					case POTYPE_TAG:
+						stru.isTag = true
+						stru.embedPtr = ptr
+					case POTYPE_EDGE:
+						stru.isEdge = true
+						stru.embedPtr = ptr
+						stru.parseEdgeOptions(field.Tag)
+					case POTYPE_RESULT:
+						stru.isResult = true
+					}
+					continue
+				}
 				if fieldType, ok3 := field.Type.(*ast.Ident); ok3 {
+					if len(field.Names) == 0 {
+						stru.parts = append(stru.parts, Part{name: fieldType.Name})
+					}
 					for _, name := range field.Names {
 						fi := Field{name: name.Name, nickname: strings.ToLower(name.Name), typeStr: fieldType.Name, comment: strings.TrimSpace(field.Comment.Text())}
 						if field.Tag != nil {
 							fi.otherIndexFields, fi.isIndex = reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Lookup("idx")
+							_, fi.isVersion = ngormOptions(field.Tag)["version"]
+							_, fi.isToken = ngormOptions(field.Tag)["token"]
 						}
 						stru.fields = append(stru.fields, fi)
 					}
-				} else if fieldType, ok := field.Type.(*ast.SelectorExpr); ok {
-					if fieldType.Sel.Name == POTYPE_TAG {
-						stru.isTag = true
-					} else if fieldType.Sel.Name == POTYPE_EDGE {
-						stru.isEdge = true
-					}
+				} else if fieldType, ok := field.Type.(*ast.ArrayType); ok {
+					stru.parseRelation(field, fieldType)
 				} else if fieldType, ok := field.Type.(*ast.StarExpr); ok {
-					if fieldType, ok := fieldType.X.(*ast.SelectorExpr); ok {
-						if fieldType.Sel.Name == POTYPE_TAG {
-							stru.isTag = true
-							// stru.fields = append(stru.fields, Field{name: "Id", nickname: "id", typeStr: "int64", comment: ""})
-						} else if fieldType.Sel.Name == POTYPE_EDGE {
-							stru.isEdge = true
-						}
+					if id, ok := fieldType.X.(*ast.Ident); ok && len(field.Names) == 0 {
+						stru.parts = append(stru.parts, Part{name: id.Name, ptr: true})
 					}
 				}
 			}
+			if stru.isResult {
+				stru.parseColumns(st)
+			}
 			f.structs = append(f.structs, stru)
 			fmt.Println(st)
 		}
@@ -381,39 +490,100 @@ func (f *File) genStruct(node ast.Node) bool {
 	return true
 }
 
+// basepoEmbed 嵌入的basepo.Tag、basepo.Edge或者basepo.Result，返回类型名以及是否以指针嵌入；
+// 有字段名或者不是basepo包中的这几个类型时返回空
+func basepoEmbed(field *ast.Field) (string, bool) {
+	if len(field.Names) != 0 {
+		return "", false
+	}
+	typ, ptr := field.Type, false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = star.X, true
+	}
+	sel, ok := typ.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "basepo" {
+		return "", false
+	}
+	switch sel.Sel.Name {
+	case POTYPE_TAG, POTYPE_EDGE, POTYPE_RESULT:
+		return sel.Sel.Name, ptr
+	}
+	return "", false
+}
+
+// memberNames 字段名，嵌入字段用类型名
+func memberNames(field *ast.Field) []string {
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(field.Names) > 0 {
+		return names
+	}
+	typ := field.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		names = append(names, t.Name)
+	case *ast.SelectorExpr:
+		names = append(names, t.Sel.Name)
+	}
+	return names
+}
+
 // help
 
 func (f *Field) toNebulaType() string {
 	return f.typeStr
 }
 
+// defaultValue 版本字段默认为0，UPSERT插入新的点或边时 version + 1 得到1
+func (f *Field) defaultValue() string {
+	if f.isVersion {
+		return " DEFAULT 0"
+	}
+	return ""
+}
+
+// funcBindVertex 从属性中绑定字段，路径中的边和点可能没有属性，缺少的属性保持零值
 func (f *Field) funcBindVertex(struct_name, prefix string) string {
-	var val string
-	var set string
-	set = struct_name + `.` + f.name + ` = ` + f.typeStr
-	if f.typeStr == "string" {
-		val = prefix + `["` + f.nickname + `"].` + "SVal"
-	} else if f.typeStr == "int" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "IVal"
-	} else if f.typeStr == "int64" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "IVal"
-	} else if f.typeStr == "int32" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "IVal"
-	} else if f.typeStr == "int16" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "IVal"
-	} else if f.typeStr == "int8" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "IVal"
-	} else if f.typeStr == "float64" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "FVal"
-	} else if f.typeStr == "float32" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "FVal"
-	} else if f.typeStr == "bool" {
-		val = "*" + prefix + `["` + f.nickname + `"].` + "BVal"
-	} else {
+	var get string
+	set := struct_name + `.` + f.name + ` = ` + f.typeStr
+	switch f.typeStr {
+	case "string":
+		get = "GetSVal()"
+	case "int", "int64", "int32", "int16", "int8":
+		get = "GetIVal()"
+	case "float64", "float32":
+		get = "GetFVal()"
+	case "bool":
+		get = "GetBVal()"
+	default:
 		panic(f.typeStr + "unsupport")
 	}
+	return `if pv, ok := ` + prefix + `["` + f.nickname + `"]; ok {
+	` + set + `(pv.` + get + `)
+}`
+}
 
-	return set + "(" + val + ")"
+// asMethod 把nebula_go.ValueWrapper转换成字段类型的方法
+func (f *Field) asMethod() string {
+	switch f.typeStr {
+	case "string":
+		return "AsString()"
+	case "int", "int64", "int32", "int16", "int8":
+		return "AsInt()"
+	case "float64", "float32":
+		return "AsFloat()"
+	case "bool":
+		return "AsBool()"
+	}
+	panic(f.typeStr + "unsupport")
 }
 
 func (f *Field) funcBindResult(struct_name, prefix string) string {
@@ -498,6 +668,12 @@ func (f *Field) funcValue(structName string) string {
 func (f *Field) funcEq(prefix string, structName string, nqlVarName string) string {
 	if f.name == IDFIELD.name {
 		return "\"id(" + nqlVarName + ")==\"+strconv.FormatInt(" + structName + ".Id(), 10)"
+	} else if f.name == SRCFIELD.name {
+		return "\"src(" + nqlVarName + ")==\"+strconv.FormatInt(" + structName + ".Src(), 10)"
+	} else if f.name == DSTFIELD.name {
+		return "\"dst(" + nqlVarName + ")==\"+strconv.FormatInt(" + structName + ".Dst(), 10)"
+	} else if f.name == RANKFIELD.name {
+		return "\"rank(" + nqlVarName + ")==\"+strconv.Itoa(" + structName + ".Rank())"
 	}
 	return "\"" + prefix + f.nickname + "==\"+" + f.funcValue(structName)
 }
@@ -506,8 +682,12 @@ func (g *Generator) funcConditionItem(s *Struct) {
 	g.Printlnf(`func (m *` + s.name + `) ConditionItem(fields ...string) []string {`)
 	g.Printlnf(`result := make([]string, 0)`)
 	fields := s.fields
+	prefix, nqlVarName := "v."+s.nickname+".", "v"
 	if s.isTag {
 		fields = append(fields, *IDFIELD)
+	} else if s.isEdge {
+		fields = append(fields, *SRCFIELD, *DSTFIELD, *RANKFIELD)
+		prefix, nqlVarName = "e.", "e"
 	}
 
 	if len(fields) > 0 {
@@ -518,7 +698,7 @@ func (g *Generator) funcConditionItem(s *Struct) {
 				g.Printf(`else `)
 			}
 			g.Printlnf(`if f == "` + f.nickname + `" {`)
-			g.Printlnf(`result = append(result,` + f.funcEq("v."+s.nickname+".", "m", "v") + `)`)
+			g.Printlnf(`result = append(result,` + f.funcEq(prefix, "m", nqlVarName) + `)`)
 			g.Printf("}")
 		}
 		g.Printlnf("\n	}")
@@ -542,17 +722,20 @@ func (g *Generator) funcBindOne(s *Struct) {
 }
 
 func (g *Generator) funcOne(s *Struct) {
+	if s.isEdge {
+		return
+	}
 	g.Printlnf(`func (m *` + s.name + `) One(session *nebula_go.Session,fields ...string) {`)
+	g.Printlnf(`	m.OneWith(session, basepo.Load{}, fields...)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`func (m *` + s.name + `) OneWith(session *nebula_go.Session, load basepo.Load, fields ...string) {`)
+	g.selectReturns(s)
+	g.mustCheckFields()
 	g.Printlnf(`var where string`)
 	g.Printlnf(`if len(fields) > 0 {`)
-	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), ",")`)
+	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), " AND ")`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "MATCH (v:` + s.nickname + `) " + where + " return id(v) as ` + s.nickname + `_id" + `)
-	g.Printlnf("`")
-	for _, f := range s.fields {
-		g.Printlnf(`	,v.` + s.nickname + `.` + f.nickname + ` as ` + s.nickname + `_` + f.nickname)
-	}
-	g.Printlnf(" limit 1`")
+	g.Printlnf(`nql := "MATCH (v:` + s.nickname + `) " + where + " return " + returns + " limit 1"`)
 	g.Printlnf(`result,err := session.Execute(nql)`)
 	g.Printlnf(`if err != nil {`)
 	g.Printlnf(`panic(err)`)
@@ -567,24 +750,83 @@ func (g *Generator) funcOne(s *Struct) {
 	g.Printlnf(`if err != nil {`)
 	g.Printlnf(`panic(err)`)
 	g.Printlnf(`}`)
-	g.Printlnf(`m.BindRecord(record)`)
+	g.Printlnf(`m.BindRecord(record, binds...)`)
+	if s.isTag {
+		g.Printlnf(`m.SetUnloaded(basepo.Without(m.AllFields(), binds...)...)`)
+		g.Printlnf(`if len(load.Preload) > 0 {`)
+		g.Printlnf(`	if err := m.Preload(session, load.Preload...); err != nil {`)
+		g.Printlnf(`		panic(err)`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`}`)
+}
+
+// checkLoaded 要修改的字段在投影查询时没有加载时返回basepo.ErrUnloadedField，避免把零值写回
+func (g *Generator) checkLoaded(s *Struct, zero string) {
+	if !s.isTag {
+		return
+	}
+	g.Printlnf(`if err := m.CheckLoaded(fields...); err != nil {`)
+	g.Printlnf(`	return ` + zero + `err`)
+	g.Printlnf(`}`)
+}
+
+// checkOrders 排序字段通过CheckFields校验，不再拼接原始字符串
+func (g *Generator) checkOrders(s *Struct) {
+	g.Printlnf(`q := ` + s.name + `Query().OrderBy(orders...)`)
+	g.Printlnf(`if err := m.CheckFields(q.OrderFields()...); err != nil {`)
+	g.Printlnf(`	panic(err)`)
+	g.Printlnf(`}`)
+}
+
+// selectReturns OneWith和ListWith只返回load.Select选择的字段，没有选择时返回全部字段；
+// 绑定后没有选择的字段记录为未加载
+func (g *Generator) selectReturns(s *Struct) {
+	g.Printlnf(`selects := load.SelectNames()`)
+	g.Printlnf(`if len(selects) == 0 {`)
+	g.Printlnf(`	selects = m.AllFields()`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := m.CheckFields(selects...); err != nil {`)
+	g.Printlnf(`	panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`returns := "id(v) as ` + s.nickname + `_id"`)
+	g.Printlnf(`binds := []string{"id"}`)
+	g.Printlnf(`for _, f := range selects {`)
+	g.Printlnf(`	if f == "id" {`)
+	g.Printlnf(`		continue`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	returns += ",v.` + s.nickname + `." + f + " as ` + s.nickname + `_" + f`)
+	g.Printlnf(`	binds = append(binds, f)`)
 	g.Printlnf(`}`)
 }
 
 func (g *Generator) funcList(s *Struct) {
-	g.Printlnf(`func (m *` + s.name + `) List(session *nebula_go.Session, ms *` + s.name + `List, offset, size int64, orderBy string, fields ...string) {`)
+	if s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) List(session *nebula_go.Session, ms *` + s.name + `List, offset, size int64, orders []basepo.Order, fields ...string) {`)
+	g.Printlnf(`	m.ListWith(session, ms, offset, size, orders, basepo.Load{}, fields...)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`func (m *` + s.name + `) ListWith(session *nebula_go.Session, ms *` + s.name + `List, offset, size int64, orders []basepo.Order, load basepo.Load, fields ...string) {`)
+	g.selectReturns(s)
+	g.mustCheckFields()
 	g.Printlnf(`var where string`)
 	g.Printlnf(`if len(fields) > 0 {`)
-	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), ",")`)
+	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), " AND ")`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "MATCH (v:` + s.nickname + `) " + where + " return id(v) as ` + s.nickname + `_id" +`)
-	for _, f := range s.fields {
-		g.Printlnf(`			",v.` + s.nickname + `.` + f.nickname + ` as ` + s.nickname + `_` + f.nickname + `" +`)
-	}
-	g.Printlnf(` ""`)
-	g.Printlnf(`	if orderBy != "" {`)
-	g.Printlnf(`		nql += " order by ` + s.nickname + `_` + `" + orderBy`)
+	g.checkOrders(s)
+	g.Printlnf(`for _, f := range q.OrderFields() {`)
+	g.Printlnf(`	returned := f == "id"`)
+	g.Printlnf(`	for _, b := range binds {`)
+	g.Printlnf(`		returned = returned || b == f`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if !returned {`)
+	g.Printlnf(`		returns += ",v.` + s.nickname + `." + f + " as ` + s.nickname + `_" + f`)
 	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+	g.Printlnf(`columns, orderBy := q.OrderNQL()`)
+	g.Printlnf(`nql := "MATCH (v:` + s.nickname + `) " + where + " return " + returns + columns + orderBy`)
 	g.Printlnf(` nql += " SKIP " + strconv.FormatInt(offset, 10) + " LIMIT " + strconv.FormatInt(size, 10)`)
 	g.Printlnf(`result,err := session.Execute(nql)`)
 	g.Printlnf(`if err != nil {`)
@@ -593,7 +835,155 @@ func (g *Generator) funcList(s *Struct) {
 	g.Printlnf(`if result.GetErrorCode() != 0 {`)
 	g.Printlnf(`	panic(result.GetErrorMsg())`)
 	g.Printlnf(`}`)
-	g.Printlnf(`ms.BindResult(result)`)
+	if s.isTag {
+		g.Printlnf(`n := len(*ms)`)
+	}
+	g.Printlnf(`ms.BindResult(result, binds...)`)
+	if s.isTag {
+		g.Printlnf(`for _, item := range (*ms)[n:] {`)
+		g.Printlnf(`	item.SetUnloaded(basepo.Without(item.AllFields(), binds...)...)`)
+		g.Printlnf(`}`)
+		g.Printlnf(`if len(load.Preload) > 0 {`)
+		g.Printlnf(`	if err := ms.Preload(session, load.Preload...); err != nil {`)
+		g.Printlnf(`		panic(err)`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`}`)
+}
+
+// funcOneEdge 边不能用MATCH (v:tag)查询，通过MATCH ()-[e:edge]->()返回整条边
+func (g *Generator) funcOneEdge(s *Struct) {
+	if !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) One(session *nebula_go.Session,fields ...string) {`)
+	g.mustCheckFields()
+	g.Printlnf(`var where string`)
+	g.Printlnf(`if len(fields) > 0 {`)
+	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), " AND ")`)
+	g.Printlnf(`}`)
+	g.Printlnf(`nql := "MATCH ()-[e:` + s.nickname + `]->() " + where + " RETURN e LIMIT 1"`)
+	g.Printlnf(`result,err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if result.GetRowSize() == 0 {`)
+	g.Printlnf(`	return`)
+	g.Printlnf(`}`)
+	g.Printlnf(`m.BindEdge(result.GetRows()[0].Values[0].GetEVal())`)
+	g.Printlnf(`}`)
+}
+
+func (g *Generator) funcListEdge(s *Struct) {
+	if !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) List(session *nebula_go.Session, ms *` + s.name + `List, offset, size int64, orders []basepo.Order, fields ...string) {`)
+	g.mustCheckFields()
+	g.Printlnf(`var where string`)
+	g.Printlnf(`if len(fields) > 0 {`)
+	g.Printlnf(`where = " WHERE " + strings.Join(m.ConditionItem(fields...), " AND ")`)
+	g.Printlnf(`}`)
+	g.checkOrders(s)
+	g.Printlnf(`columns, orderBy := q.OrderNQL()`)
+	g.Printlnf(`nql := "MATCH ()-[e:` + s.nickname + `]->() " + where + " RETURN e" +`)
+	for _, f := range []*Field{SRCFIELD, DSTFIELD, RANKFIELD} {
+		g.Printlnf(`			",` + f.nickname + `(e) as ` + s.nickname + `_` + f.nickname + `" +`)
+	}
+	for _, f := range s.fields {
+		g.Printlnf(`			",e.` + f.nickname + ` as ` + s.nickname + `_` + f.nickname + `" +`)
+	}
+	g.Printlnf(` columns + orderBy`)
+	g.Printlnf(` nql += " SKIP " + strconv.FormatInt(offset, 10) + " LIMIT " + strconv.FormatInt(size, 10)`)
+	g.Printlnf(`result,err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`ms.BindEdges(result, "e")`)
+	g.Printlnf(`}`)
+}
+
+// funcFetchEdge 按起点、终点和rank读取边的属性
+func (g *Generator) funcFetchEdge(s *Struct) {
+	if !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Fetch(session *nebula_go.Session) bool {`)
+	g.Printlnf(`nql := "FETCH PROP ON ` + s.nickname + ` " + strconv.FormatInt(m.Src(), 10) + "->" + strconv.FormatInt(m.Dst(), 10) + "@" + strconv.Itoa(m.Rank()) + " YIELD edge AS e"`)
+	g.Printlnf(`result,err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if result.GetRowSize() == 0 {`)
+	g.Printlnf(`	return false`)
+	g.Printlnf(`}`)
+	g.Printlnf(`m.BindEdge(result.GetRows()[0].Values[0].GetEVal())`)
+	g.Printlnf(`return true`)
+	g.Printlnf(`}`)
+}
+
+// funcGet 按id通过FETCH PROP加载，比One的MATCH轻很多
+func (g *Generator) funcGet(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Get(session *nebula_go.Session, id int64) error {`)
+	g.Printlnf(`	nql := "FETCH PROP ON ` + s.nickname + ` " + strconv.FormatInt(id, 10) + " YIELD vertex AS v"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if result.GetRowSize() == 0 {`)
+	g.Printlnf(`		return fmt.Errorf("%%w: ` + s.nickname + ` %%d", basepo.ErrNotFound, id)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	m.BindVertex(result.GetRows()[0].Values[0].GetVVal())`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcGetMany 按basepo.DefaultBatchSize分批FETCH，结果和ids的顺序一致，不存在的id通过missing返回
+func (g *Generator) funcGetMany(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) GetMany(session *nebula_go.Session, ids []int64) (` + s.name + `List, []int64, error) {`)
+	g.Printlnf(`	found := make(map[int64]*` + s.name + `, len(ids))`)
+	g.Printlnf(`	for _, chunk := range basepo.Chunks(len(ids), 0) {`)
+	g.Printlnf(`		vids := make([]string, 0, chunk.End-chunk.Start)`)
+	g.Printlnf(`		for _, id := range ids[chunk.Start:chunk.End] {`)
+	g.Printlnf(`			vids = append(vids, strconv.FormatInt(id, 10))`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		nql := "FETCH PROP ON ` + s.nickname + ` " + strings.Join(vids, ",") + " YIELD vertex AS v"`)
+	g.Printlnf(`		result, err := session.Execute(nql)`)
+	g.Printlnf(`		if err != nil {`)
+	g.Printlnf(`			return nil, nil, err`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`			return nil, nil, err`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		for _, row := range result.GetRows() {`)
+	g.Printlnf(`			t := &` + s.name + `{}`)
+	g.Printlnf(`			t.BindVertex(row.Values[0].GetVVal())`)
+	g.Printlnf(`			found[t.Id()] = t`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	ms := make(` + s.name + `List, 0, len(ids))`)
+	g.Printlnf(`	var missing []int64`)
+	g.Printlnf(`	for _, id := range ids {`)
+	g.Printlnf(`		if t, ok := found[id]; ok {`)
+	g.Printlnf(`			ms = append(ms, t)`)
+	g.Printlnf(`		} else {`)
+	g.Printlnf(`			missing = append(missing, id)`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return ms, missing, nil`)
 	g.Printlnf(`}`)
 }
 
@@ -602,14 +992,11 @@ func (g *Generator) funcInsertTag(s *Struct) {
 		return
 	}
 	g.Printlnf(`func (m *` + s.name + `) Insert(session *nebula_go.Session, fields ...string) {`)
-	g.Printlnf(`if len(fields) == 0 {`)
-	g.Printlnf(`fields = m.AllFields()`)
+	g.Printlnf(`if err := m.InsertWith(session, basepo.ConflictOverwrite, fields...); err != nil {`)
+	g.Printlnf(`	panic(err)`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "insert VERTEX " + m.TagName() +"("+m.NqlNames(fields...)+") VALUES " + 
-	strconv.FormatInt(m.Id2(),10) + ":(" + m.NqlValues(fields...)+ ")"`)
-	g.Printlnf(`result,_ := session.Execute(nql)`)
-	g.Printlnf(`checkResultSet(nql, result)`)
 	g.Printlnf("}")
+	g.funcInsertWith(s, `strconv.FormatInt(m.Id2(),10)`, `"INSERT VERTEX "`, `m.TagName()`, `YIELD id(vertex)`)
 }
 
 func (g *Generator) funcInsertEdge(s *Struct) {
@@ -617,52 +1004,420 @@ func (g *Generator) funcInsertEdge(s *Struct) {
 		return
 	}
 	g.Printlnf(`func (m *` + s.name + `) Insert(session *nebula_go.Session, fields ...string) {`)
+	g.Printlnf(`if err := m.InsertWith(session, basepo.ConflictOverwrite, fields...); err != nil {`)
+	g.Printlnf(`	panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf("}")
+	g.funcInsertWith(s, edgeKey, `"INSERT EDGE "`, `m.EdgeName()`, `YIELD src(edge)`)
+}
+
+// funcInsertWith 插入时根据conflict处理已存在的点或边：覆盖、忽略（IF NOT EXISTS）或者返回basepo.ErrAlreadyExists
+func (g *Generator) funcInsertWith(s *Struct, key, insert, name, yield string) {
+	g.Printlnf(`func (m *` + s.name + `) InsertWith(session *nebula_go.Session, conflict basepo.Conflict, fields ...string) error {`)
 	g.Printlnf(`if len(fields) == 0 {`)
 	g.Printlnf(`fields = m.AllFields()`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "insert EDGE " + m.EdgeName() +"("+m.NqlNames(fields...)+") VALUES " + 
-	strconv.FormatInt(m.Src(),10) + "->" + strconv.FormatInt(m.Dst(),10) + ":(" + m.NqlValues(fields...)+ ")"`)
-	g.Printlnf(`result,_ := session.Execute(nql)`)
-	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	v := s.versionField()
+	if v != nil {
+		g.Printlnf(`bump := conflict == basepo.ConflictOverwrite`)
+		g.Printlnf(`if bump {`)
+		g.Printlnf(`	fields = append(basepo.Without(fields, "` + v.nickname + `"), "` + v.nickname + `")`)
+		g.Printlnf(`	m.` + v.name + `++`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`key := ` + key)
+	g.Printlnf(`nql := ` + insert)
+	g.Printlnf(`if conflict != basepo.ConflictOverwrite {`)
+	g.Printlnf(`	nql += "IF NOT EXISTS "`)
+	g.Printlnf(`}`)
+	g.Printlnf(`nql += ` + name + ` + "(" + m.NqlNames(fields...) + ") VALUES " + key + ":(" + m.NqlValues(fields...) + ")"`)
+	if v != nil {
+		g.Printlnf(`if bump {`)
+		g.Printlnf(`	m.` + v.name + `--`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`if conflict == basepo.ConflictFail {`)
+	g.Printlnf(`	exist := "FETCH PROP ON " + ` + name + ` + " " + key + " ` + yield + `"`)
+	g.Printlnf(`	result, err := session.Execute(exist)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(exist, result); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if result.GetRowSize() > 0 {`)
+	g.Printlnf(`		return fmt.Errorf("%%w: %%s %%s", basepo.ErrAlreadyExists, ` + name + `, key)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	if v == nil {
+		g.Printlnf(`return resultError(nql, result)`)
+		g.Printlnf("}")
+		return
+	}
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if bump {`)
+	g.Printlnf(`	m.` + v.name + `++`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return nil`)
 	g.Printlnf("}")
 }
 
-func (g *Generator) funcUpdateTag(s *Struct) {
-	if !s.isTag {
+// funcInsertBatch 多行合并成一条INSERT语句，每段最多size行，size<=0时使用basepo.DefaultBatchSize，返回失败的段
+func (g *Generator) funcInsertBatch(s *Struct) {
+	if !s.isTag && !s.isEdge {
 		return
 	}
-	g.Printlnf(`func (m *` + s.name + `) Update(session *nebula_go.Session, fields ...string) {`)
+	g.Printlnf(`func (m *` + s.name + `) InsertBatch(session *nebula_go.Session, ms []*` + s.name + `, size int, fields ...string) error {`)
 	g.Printlnf(`if len(fields) == 0 {`)
 	g.Printlnf(`fields = m.AllFields()`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "Update VERTEX ON " + m.TagName() + " " + strconv.FormatInt(m.Id(), 10) +" SET "+ strings.Join(m.NqlNameValues("=", fields...), ",")`)
-	g.Printlnf(`result,_ := session.Execute(nql)`)
-	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	v := s.versionField()
+	if v != nil {
+		g.Printlnf(`fields = append(basepo.Without(fields, "` + v.nickname + `"), "` + v.nickname + `")`)
+	}
+	g.Printlnf(`var errs basepo.BatchError`)
+	g.Printlnf(`for _, chunk := range basepo.Chunks(len(ms), size) {`)
+	g.Printlnf(`	values := make([]string, 0, chunk.End-chunk.Start)`)
+	g.Printlnf(`	for _, item := range ms[chunk.Start:chunk.End] {`)
+	if v != nil {
+		g.Printlnf(`		item.` + v.name + `++`)
+	}
+	if s.isTag {
+		g.Printlnf(`		values = append(values, strconv.FormatInt(item.Id2(), 10)+":("+item.NqlValues(fields...)+")")`)
+		g.versionRollback(v)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	nql := "INSERT VERTEX " + m.TagName() + "(" + m.NqlNames(fields...) + ") VALUES " + strings.Join(values, ",")`)
+	} else {
+		g.Printlnf(`		values = append(values, strconv.FormatInt(item.Src(), 10)+"->"+strconv.FormatInt(item.Dst(), 10)+"@"+strconv.Itoa(item.Rank())+":("+item.NqlValues(fields...)+")")`)
+		g.versionRollback(v)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	nql := "INSERT EDGE " + m.EdgeName() + "(" + m.NqlNames(fields...) + ") VALUES " + strings.Join(values, ",")`)
+	}
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err == nil {`)
+	g.Printlnf(`		err = resultError(nql, result)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		errs = append(errs, &basepo.ChunkError{Chunk: chunk, Err: err})`)
+	if v != nil {
+		g.Printlnf(`		continue`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	for _, item := range ms[chunk.Start:chunk.End] {`)
+		g.Printlnf(`		item.` + v.name + `++`)
+	}
+	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if len(errs) > 0 {`)
+	g.Printlnf(`	return errs`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return nil`)
 	g.Printlnf("}")
 }
 
+// versionRollback 拼接语句时临时加一的版本，写入成功后再加一
+func (g *Generator) versionRollback(v *Field) {
+	if v != nil {
+		g.Printlnf(`		item.` + v.name + `--`)
+	}
+}
+
+func (g *Generator) funcUpdateTag(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.funcUpdate(s, `"UPDATE VERTEX ON " + m.TagName() + " " + strconv.FormatInt(m.Id(), 10)`)
+}
+
 func (g *Generator) funcUpdateEdge(s *Struct) {
 	if !s.isEdge {
 		return
 	}
-	g.Printlnf(`func (m *` + s.name + `) Update(session *nebula_go.Session, id int64, fields ...string) {`)
+	g.funcUpdate(s, `"UPDATE EDGE ON " + m.EdgeName() + " " + `+edgeKey)
+}
+
+// funcUpdate 有版本字段时通过 WHEN version == <old> 实现乐观锁，其他人已经修改时返回basepo.ErrStaleObject
+func (g *Generator) funcUpdate(s *Struct, update string) {
+	g.Printlnf(`func (m *` + s.name + `) Update(session *nebula_go.Session, fields ...string) error {`)
 	g.Printlnf(`if len(fields) == 0 {`)
 	g.Printlnf(`fields = m.AllFields()`)
 	g.Printlnf(`}`)
-	g.Printlnf(`nql := "Update VERTEX ON " + m.EdgeName() + " " + strconv.FormatInt(m.Src(), 10) + " -> " + strconv.FormatInt(m.Dst(), 10) +" SET " + strings.Join(m.NqlNameValues("="), ",")`)
-	g.Printlnf(`result,_ := session.Execute(nql)`)
-	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.checkLoaded(s, "")
+	v := s.versionField()
+	if v == nil {
+		g.Printlnf(`nql := ` + update + ` + " SET " + strings.Join(m.NqlNameValues("=", fields...), ",")`)
+		g.Printlnf(`result, err := session.Execute(nql)`)
+		g.Printlnf(`if err != nil {`)
+		g.Printlnf(`	return err`)
+		g.Printlnf(`}`)
+		g.Printlnf(`return resultError(nql, result)`)
+		g.Printlnf("}")
+		return
+	}
+	g.Printlnf(`sets := make([]string, 0, len(fields))`)
+	g.Printlnf(`for _, f := range fields {`)
+	g.Printlnf(`	if f != "` + v.nickname + `" {`)
+	g.Printlnf(`		sets = append(sets, f)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+	g.Printlnf(`nql := ` + update + ` +`)
+	g.Printlnf(`	" SET " + strings.Join(append(m.NqlNameValues("=", sets...), "` + v.nickname + ` = ` + v.nickname + ` + 1"), ",") +`)
+	g.Printlnf(`	" WHEN ` + v.nickname + ` == " + strconv.FormatInt(m.` + v.name + `, 10) +`)
+	g.Printlnf(`	" YIELD (" + strings.Join(append(m.NqlNameValues("==", sets...), "` + v.nickname + ` == " + strconv.FormatInt(m.` + v.name + `+1, 10)), " AND ") + ") AS matched"`)
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`stale := fmt.Errorf("%%w: ` + s.nickname + ` version %%d", basepo.ErrStaleObject, m.` + v.name + `)`)
+	g.Printlnf(`if result.GetRowSize() == 0 {`)
+	g.Printlnf(`	return stale`)
+	g.Printlnf(`}`)
+	g.Printlnf(`matched, err := result.GetValuesByColName("matched")`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if ok, _ := matched[0].AsBool(); !ok {`)
+	g.Printlnf(`	return stale`)
+	g.Printlnf(`}`)
+	g.Printlnf(`m.` + v.name + `++`)
+	g.Printlnf(`return nil`)
+	g.Printlnf("}")
+}
+
+// funcUpdateExpr 在服务端计算的修改，例如计数器自增，修改后的值绑定回实体
+func (g *Generator) funcUpdateExpr(s *Struct) {
+	if (!s.isTag && !s.isEdge) || len(s.fields) == 0 {
+		return
+	}
+	update := `"UPDATE VERTEX ON " + m.TagName() + " " + strconv.FormatInt(m.Id(), 10)`
+	if s.isEdge {
+		update = `"UPDATE EDGE ON " + m.EdgeName() + " " + ` + edgeKey
+	}
+	g.Printlnf(`func (m *` + s.name + `) UpdateExpr(session *nebula_go.Session, assigns ...basepo.Assign) error {`)
+	g.Printlnf(`if len(assigns) == 0 {`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+	g.Printlnf(`fields := make([]string, 0, len(assigns))`)
+	g.Printlnf(`sets := make([]string, 0, len(assigns))`)
+	g.Printlnf(`yields := make([]string, 0, len(assigns))`)
+	g.Printlnf(`for _, a := range assigns {`)
+	g.Printlnf(`	f := a.Field().Name()`)
+	g.Printlnf(`	fields = append(fields, f)`)
+	g.Printlnf(`	sets = append(sets, a.NQL())`)
+	g.Printlnf(`	yields = append(yields, f+" AS ` + s.nickname + `_"+f)`)
+	g.Printlnf(`}`)
+	if v := s.versionField(); v != nil {
+		g.Printlnf(`sets = append(sets, "` + v.nickname + ` = ` + v.nickname + ` + 1")`)
+		g.Printlnf(`yields = append(yields, "` + v.nickname + ` AS ` + s.nickname + `_` + v.nickname + `")`)
+		g.Printlnf(`fields = append(fields, "` + v.nickname + `")`)
+	}
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`nql := ` + update + ` + " SET " + strings.Join(sets, ",") + " YIELD " + strings.Join(yields, ",")`)
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if result.GetRowSize() > 0 {`)
+	g.Printlnf(`	record, err := result.GetRowValuesByIndex(0)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	m.BindRecord(record, fields...)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return nil`)
+	g.Printlnf("}")
+}
+
+// yields UPDATE/UPSERT语句YIELD的列，列名和BindRecord一致
+func (s *Struct) yields() string {
+	items := make([]string, 0, len(s.fields))
+	for _, f := range s.fields {
+		items = append(items, f.nickname+" AS "+s.nickname+"_"+f.nickname)
+	}
+	return strings.Join(items, ",")
+}
+
+// funcUpsert 不存在时插入，存在时在满足条件后修改，修改后的值绑定回实体
+func (g *Generator) funcUpsert(s *Struct) {
+	if (!s.isTag && !s.isEdge) || len(s.fields) == 0 {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Upsert(session *nebula_go.Session, cond basepo.Cond, fields ...string) error {`)
+	g.Printlnf(`if len(fields) == 0 {`)
+	g.Printlnf(`fields = m.AllFields()`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.checkLoaded(s, "")
+	if v := s.versionField(); v != nil {
+		g.Printlnf(`assigns := append(m.NqlNameValues("=", basepo.Without(fields, "` + v.nickname + `")...), "` + v.nickname + ` = ` + v.nickname + ` + 1")`)
+	} else {
+		g.Printlnf(`assigns := m.NqlNameValues("=", fields...)`)
+	}
+	if s.isTag {
+		g.Printlnf(`nql := "UPSERT VERTEX ON " + m.TagName() + " " + strconv.FormatInt(m.Id2(), 10) + " SET " + strings.Join(assigns, ",")`)
+	} else {
+		g.Printlnf(`nql := "UPSERT EDGE ON " + m.EdgeName() + " " + ` + edgeKey + ` + " SET " + strings.Join(assigns, ",")`)
+	}
+	g.Printlnf(`if cond != nil {`)
+	g.Printlnf(`	nql += " WHEN " + basepo.WhenNQL(cond)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`nql += " YIELD ` + s.yields() + `"`)
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if result.GetRowSize() > 0 {`)
+	g.Printlnf(`	record, err := result.GetRowValuesByIndex(0)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	m.BindRecord(record, m.AllFields()...)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return nil`)
 	g.Printlnf("}")
 }
 
+// edgeKey 边在语句中的表示 src->dst@rank
+const edgeKey = `strconv.FormatInt(m.Src(), 10) + "->" + strconv.FormatInt(m.Dst(), 10) + "@" + strconv.Itoa(m.Rank())`
+
+// funcUpdateWhen 条件修改，返回条件是否满足。
+// 条件不满足时nebula不修改并返回原来的值，原来的值可能已经被其他人改成了相同的值，
+// 有token字段时SET中同时写入这次调用生成的随机token，YIELD出的token等于它才说明条件满足；
+// 没有token字段时和带版本的Update一样比较YIELD出的值，其他人同时写入相同的值时也会返回true
+func (g *Generator) funcUpdateWhen(s *Struct) {
+	if (!s.isTag && !s.isEdge) || len(s.fields) == 0 {
+		return
+	}
+	t := s.tokenField()
+	v := s.versionField()
+	g.Printlnf(`func (m *` + s.name + `) UpdateWhen(session *nebula_go.Session, cond basepo.Cond, fields ...string) (bool, error) {`)
+	g.Printlnf(`if len(fields) == 0 {`)
+	g.Printlnf(`fields = m.AllFields()`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := m.CheckProps(fields...); err != nil {`)
+	g.Printlnf(`	return false, err`)
+	g.Printlnf(`}`)
+	g.checkLoaded(s, "false, ")
+	switch {
+	case t != nil && v != nil:
+		g.Printlnf(`token := basepo.NewToken()`)
+		g.Printlnf(`assigns := append(m.NqlNameValues("=", basepo.Without(fields, "` + t.nickname + `", "` + v.nickname + `")...), "` + t.nickname + ` = " + strconv.Quote(token))`)
+		g.Printlnf(`assigns = append(assigns, "` + v.nickname + ` = ` + v.nickname + ` + 1")`)
+	case t != nil:
+		g.Printlnf(`token := basepo.NewToken()`)
+		g.Printlnf(`assigns := append(m.NqlNameValues("=", basepo.Without(fields, "` + t.nickname + `")...), "` + t.nickname + ` = " + strconv.Quote(token))`)
+	case v != nil:
+		g.Printlnf(`sets := basepo.Without(fields, "` + v.nickname + `")`)
+		g.Printlnf(`assigns := append(m.NqlNameValues("=", sets...), "` + v.nickname + ` = ` + v.nickname + ` + 1")`)
+	default:
+		g.Printlnf(`sets := fields`)
+		g.Printlnf(`assigns := m.NqlNameValues("=", sets...)`)
+	}
+	if s.isTag {
+		g.Printlnf(`nql := "UPDATE VERTEX ON " + m.TagName() + " " + strconv.FormatInt(m.Id(), 10) + " SET " + strings.Join(assigns, ",")`)
+	} else {
+		g.Printlnf(`nql := "UPDATE EDGE ON " + m.EdgeName() + " " + ` + edgeKey + ` + " SET " + strings.Join(assigns, ",")`)
+	}
+	g.Printlnf(`if cond != nil {`)
+	g.Printlnf(`	nql += " WHEN " + basepo.WhenNQL(cond)`)
+	g.Printlnf(`}`)
+	if t != nil {
+		g.Printlnf(`nql += " YIELD ` + s.yields() + `"`)
+	} else {
+		g.Printlnf(`matched := "true"`)
+		g.Printlnf(`if len(sets) > 0 {`)
+		g.Printlnf(`	matched = "(" + strings.Join(m.NqlNameValues("==", sets...), " AND ") + ")"`)
+		g.Printlnf(`}`)
+		g.Printlnf(`nql += " YIELD ` + s.yields() + `," + matched + " AS matched"`)
+	}
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return false, err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return false, err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if result.GetRowSize() == 0 {`)
+	g.Printlnf(`	return false, nil`)
+	g.Printlnf(`}`)
+	g.Printlnf(`record, err := result.GetRowValuesByIndex(0)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return false, err`)
+	g.Printlnf(`}`)
+	if t != nil {
+		g.Printlnf(`val, err := record.GetValueByColName("` + s.nickname + `_` + t.nickname + `")`)
+		g.Printlnf(`if err != nil {`)
+		g.Printlnf(`	return false, err`)
+		g.Printlnf(`}`)
+		g.Printlnf(`if got, _ := val.AsString(); got != token {`)
+		g.Printlnf(`	return false, nil`)
+		g.Printlnf(`}`)
+	} else {
+		g.Printlnf(`val, err := record.GetValueByColName("matched")`)
+		g.Printlnf(`if err != nil {`)
+		g.Printlnf(`	return false, err`)
+		g.Printlnf(`}`)
+		g.Printlnf(`if ok, _ := val.AsBool(); !ok {`)
+		g.Printlnf(`	return false, nil`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`m.BindRecord(record, m.AllFields()...)`)
+	g.Printlnf(`return true, nil`)
+	g.Printlnf("}")
+}
+
+// funcRemoveTag 默认只删除当前的Tag，点上的其他Tag和边都保留；
+// RemoveVertex删除整个点但保留边，RemoveWithEdge连同边一起删除
 func (g *Generator) funcRemoveTag(s *Struct) {
 	if !s.isTag {
 		return
 	}
 	g.Printlnf(`func (m *` + s.name + `) RemoveById(session *nebula_go.Session) {`)
-	g.Printlnf(`nql := "DELETE VERTEX " + strconv.FormatInt(m.Id(),10) + " WITH EDGE;"`)
-	g.Printlnf(`result,_ := session.Execute(nql)`)
-	g.Printlnf(`checkResultSet(nql, result)`)
+	g.Printlnf(`if err := m.RemoveTag(session); err != nil {`)
+	g.Printlnf(`	panic(err)`)
+	g.Printlnf(`}`)
+	g.Printlnf("}")
+
+	g.funcRemove(s, "RemoveTag", `"DELETE TAG " + m.TagName() + " FROM " + strconv.FormatInt(m.Id(), 10)`)
+	g.funcRemove(s, "RemoveVertex", `"DELETE VERTEX " + strconv.FormatInt(m.Id(), 10)`)
+	g.funcRemove(s, "RemoveWithEdge", `"DELETE VERTEX " + strconv.FormatInt(m.Id(), 10) + " WITH EDGE"`)
+}
+
+func (g *Generator) funcRemove(s *Struct, method, nql string) {
+	g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session) error {`)
+	g.Printlnf(`nql := ` + nql)
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return resultError(nql, result)`)
 	g.Printlnf("}")
 }
 
@@ -701,7 +1456,9 @@ func (g *Generator) funcBindVertex(s *Struct) {
 		return
 	}
 	g.Printlnf(`func (m * ` + s.name + `) BindVertex(v *nebula.Vertex) {`)
+	g.initTag(s)
 	g.Printlnf(`	m.SetId(*v.Vid.IVal)`)
+	g.Printlnf(`	m.SetUnloaded()`)
 	g.Printlnf(`	for _, tag := range v.Tags {`)
 	g.Printlnf(`	if string(tag.Name) != "` + s.nickname + `" {`)
 	g.Printlnf(`		continue`)
@@ -716,6 +1473,55 @@ func (g *Generator) funcBindVertex(s *Struct) {
 	g.Printlnf(`}`)
 }
 
+// initTag 指针方式嵌入的basepo.Tag在绑定前需要先分配
+func (g *Generator) initTag(s *Struct) {
+	g.initTagVar(s, "m")
+}
+
+func (g *Generator) initTagVar(s *Struct, v string) {
+	if !s.isTag || !s.embedPtr {
+		return
+	}
+	g.Printlnf(`	if ` + v + `.Tag == nil {`)
+	g.Printlnf(`		` + v + `.Tag = &basepo.Tag{}`)
+	g.Printlnf(`	}`)
+}
+
+// funcBindEdge 绑定nebula.Edge，包括起点、终点和rank
+func (g *Generator) funcBindEdge(s *Struct) {
+	if !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) BindEdge(e *nebula.Edge) {`)
+	g.Printlnf(`	src, dst := *e.Src.IVal, *e.Dst.IVal`)
+	g.Printlnf(`	if e.Type < 0 {`)
+	g.Printlnf(`		src, dst = dst, src`)
+	g.Printlnf(`	}`)
+	if s.embedPtr {
+		g.Printlnf(`	m.Edge = basepo.NewEdgeWithRank(src, dst, int(e.Ranking))`)
+	} else {
+		g.Printlnf(`	m.Edge = *basepo.NewEdgeWithRank(src, dst, int(e.Ranking))`)
+	}
+	for _, f := range s.fields {
+		g.Printlnf(f.funcBindVertex("m", `e.Props`))
+	}
+	g.Printlnf(`}`)
+}
+
+func (g *Generator) funcBindEdges(s *Struct) {
+	if !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (ms *` + s.name + `List) BindEdges(result *nebula_go.ResultSet, col string) {`)
+	g.Printlnf(`	idx := colIndex(result, col)`)
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	g.Printlnf(`		m := &` + s.name + `{}`)
+	g.Printlnf(`		m.BindEdge(row.Values[idx].GetEVal())`)
+	g.Printlnf(`		*ms = append(*ms, m)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+}
+
 func (g *Generator) funcBindTag(s *Struct) {
 	if !s.isTag {
 		return
@@ -734,12 +1540,15 @@ func (g *Generator) funcBindRecord(s *Struct) {
 	g.Printlnf(`if len(fields) == 0 {`)
 	g.Printlnf(`fields = m.AllFieldsWithId()`)
 	g.Printlnf(`}`)
+	g.mustCheckFields()
+	fields := s.fields
 	if s.isTag {
-		g.Printlnf(IDFIELD.funcBindResult("m", s.nickname+"_"))
+		g.initTag(s)
+		fields = append([]Field{*IDFIELD}, fields...)
 	}
-	if len(s.fields) > 0 {
+	if len(fields) > 0 {
 		g.Printlnf("	for _, f := range fields {")
-		for i, f := range s.fields {
+		for i, f := range fields {
 			if i != 0 {
 				g.Printf(`else `)
 			}
@@ -749,6 +1558,10 @@ func (g *Generator) funcBindRecord(s *Struct) {
 		}
 		g.Printlnf("\n	}")
 	}
+	// 只把绑定的字段标记为已加载，投影查询由One和List记录未加载的字段
+	if s.isTag {
+		g.Printlnf(`m.MarkLoaded(fields...)`)
+	}
 
 	g.Printlnf("}")
 }
@@ -769,7 +1582,9 @@ func (g *Generator) funcAllFieldsWithId(s *Struct) {
 	for _, f := range s.fields {
 		g.Printf(`		"` + f.nickname + `",`)
 	}
-	g.Printf(`		"id",`)
+	if s.isTag {
+		g.Printf(`		"id",`)
+	}
 	g.Printlnf(`	}`)
 	g.Printlnf(`}`)
 }
@@ -778,6 +1593,7 @@ func (g *Generator) funcTagName(s *Struct) {
 	if !s.isTag {
 		return
 	}
+	g.Printlnf(`var _ basepo.ITag = (*` + s.name + `)(nil)`)
 	g.Printlnf(`func (m *` + s.name + `) TagName() string {`)
 	g.Printlnf(`	return "` + s.nickname + `"`)
 	g.Printlnf(`}`)
@@ -787,6 +1603,7 @@ func (g *Generator) funcEdgeName(s *Struct) {
 	if !s.isEdge {
 		return
 	}
+	g.Printlnf(`var _ basepo.IEdge = (*` + s.name + `)(nil)`)
 	g.Printlnf(`func (m *` + s.name + `) EdgeName() string {`)
 	g.Printlnf(`	return "` + s.nickname + `"`)
 	g.Printlnf(`}`)
@@ -879,7 +1696,7 @@ func (g *Generator) CreateTag(s *Struct) {
 	g.Printlnf(`func (m *` + s.name + `) Create(session *nebula_go.Session) {`)
 	g.Printlnf("	nql:=`CREATE TAG IF NOT EXISTS ` + m.TagName() + `(")
 	for i, f := range s.fields {
-		g.Printf("		" + f.nickname + "			" + f.toNebulaType() + "			COMMENT '" + f.comment + "'")
+		g.Printf("		" + f.nickname + "			" + f.toNebulaType() + f.defaultValue() + "			COMMENT '" + f.comment + "'")
 		if i != len(s.fields)-1 {
 			g.Printlnf(",")
 		}
@@ -906,7 +1723,7 @@ func (g *Generator) CreateEdge(s *Struct) {
 	g.Printlnf(`func (m *` + s.name + `) Create(session *nebula_go.Session) {`)
 	g.Printlnf("	nql := `CREATE EDGE IF NOT EXISTS ` + m.EdgeName() + `(")
 	for i, f := range s.fields {
-		g.Printf("		" + f.nickname + "			" + f.toNebulaType() + "			COMMENT '" + f.comment + "'")
+		g.Printf("		" + f.nickname + "			" + f.toNebulaType() + f.defaultValue() + "			COMMENT '" + f.comment + "'")
 		if i != len(s.fields)-1 {
 			g.Printlnf(",")
 		}
@@ -923,5 +1740,120 @@ func (g *Generator) checkResultSet() {
 		if !res.IsSucceed() {
 			panic(fmt.Sprintf("%s, ErrorCode: %v, ErrorMsg: %s", prefix, res.GetErrorCode(), res.GetErrorMsg()))
 		}
+	}
+
+	func resultError(prefix string, res *nebula_go.ResultSet) error {
+		if !res.IsSucceed() {
+			return fmt.Errorf("%s, ErrorCode: %v, ErrorMsg: %s", prefix, res.GetErrorCode(), res.GetErrorMsg())
+		}
+		return nil
+	}
+
+	func colIndex(res *nebula_go.ResultSet, col string) int {
+		i, err := lookupCol(res, col)
+		if err != nil {
+			panic(err)
+		}
+		return i
+	}
+
+	func lookupCol(res *nebula_go.ResultSet, col string) (int, error) {
+		for i, name := range res.GetColNames() {
+			if name == col {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("column %s not found in %v", col, res.GetColNames())
+	}
+
+	func aggregateValue(session *nebula_go.Session, nql string) (*nebula_go.ValueWrapper, error) {
+		result, err := session.Execute(nql)
+		if err != nil {
+			return nil, err
+		}
+		if err := resultError(nql, result); err != nil {
+			return nil, err
+		}
+		if result.GetRowSize() == 0 {
+			return nil, basepo.ErrNoRows
+		}
+		record, err := result.GetRowValuesByIndex(0)
+		if err != nil {
+			return nil, err
+		}
+		val, err := record.GetValueByColName("value")
+		if err != nil {
+			return nil, err
+		}
+		if val.IsNull() {
+			return nil, basepo.ErrNoRows
+		}
+		return val, nil
+	}
+
+	// deleteEdges 删除点上over的所有边并按边的类型计数，over可以是 "*" 加方向
+	func deleteEdges(session *nebula_go.Session, id, over string, report *basepo.RemoveReport) error {
+		nql := "GO FROM " + id + " OVER " + over + " YIELD type(edge) AS type, src(edge) AS src, dst(edge) AS dst, rank(edge) AS rank"
+		result, err := session.Execute(nql)
+		if err != nil {
+			return err
+		}
+		if err := resultError(nql, result); err != nil {
+			return err
+		}
+		// 自环在BIDIRECT中会出现两次
+		seen := make(map[string]bool, result.GetRowSize())
+		types := make([]string, 0)
+		keys := make(map[string][]string)
+		for _, row := range result.GetRows() {
+			typ := string(row.Values[0].GetSVal())
+			key := strconv.FormatInt(row.Values[1].GetIVal(), 10) + "->" + strconv.FormatInt(row.Values[2].GetIVal(), 10) + "@" + strconv.FormatInt(row.Values[3].GetIVal(), 10)
+			if seen[typ+" "+key] {
+				continue
+			}
+			seen[typ+" "+key] = true
+			if _, ok := keys[typ]; !ok {
+				types = append(types, typ)
+			}
+			keys[typ] = append(keys[typ], key)
+		}
+		for _, typ := range types {
+			for _, chunk := range basepo.Chunks(len(keys[typ]), 0) {
+				nql := "DELETE EDGE " + typ + " " + strings.Join(keys[typ][chunk.Start:chunk.End], ",")
+				result, err := session.Execute(nql)
+				if err == nil {
+					err = resultError(nql, result)
+				}
+				if err != nil {
+					return err
+				}
+				report.Edges[typ] += chunk.End - chunk.Start
+			}
+		}
+		return nil
+	}
+
+	// otherTags 点上除了tag以外是否还有其他Tag
+	func otherTags(session *nebula_go.Session, id, tag string) (bool, error) {
+		nql := "FETCH PROP ON * " + id + " YIELD tags(vertex) AS tags"
+		result, err := session.Execute(nql)
+		if err != nil {
+			return false, err
+		}
+		if err := resultError(nql, result); err != nil {
+			return false, err
+		}
+		for _, row := range result.GetRows() {
+			list := row.Values[0].GetLVal()
+			if list == nil {
+				continue
+			}
+			for _, v := range list.Values {
+				if string(v.GetSVal()) != tag {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
 	}`)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// generateDir 不经过packages.Load，直接解析目录中的文件生成代码
+func generateDir(t *testing.T, dir string) []byte {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("%d packages found in %s", len(pkgs), dir)
+	}
+	g := Generator{}
+	for name, pkg := range pkgs {
+		g.pkg = &Package{name: name}
+		// 按文件名排序，保证输出稳定
+		names := make([]string, 0, len(pkg.Files))
+		for path := range pkg.Files {
+			names = append(names, path)
+		}
+		sort.Strings(names)
+		for _, path := range names {
+			g.pkg.files = append(g.pkg.files, &File{
+				file:    pkg.Files[path],
+				pkg:     g.pkg,
+				structs: make([]Struct, 0),
+			})
+		}
+	}
+	g.header([]string{dir})
+	g.generate(dir, nil)
+	return g.format()
+}
+
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("testdata", "fixture")
+	got := generateDir(t, dir)
+	golden := filepath.Join("testdata", "fixture.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from %s, update it with go test -update and review the git diff", golden)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+// pathHelpers 把nebula.Path拆成点和边，边的起点是上一步到达的点；listValues取子图中的列表
+func (g *Generator) pathHelpers() {
+	g.Printlnf("%s", `
+	func pathSteps(p *nebula.Path) ([]*nebula.Vertex, []*nebula.Edge) {
+		vertices := []*nebula.Vertex{p.Src}
+		edges := make([]*nebula.Edge, 0, len(p.Steps))
+		src := p.Src
+		for _, step := range p.Steps {
+			edges = append(edges, &nebula.Edge{Src: src.Vid, Dst: step.Dst.Vid, Type: step.Type, Name: step.Name, Ranking: step.Ranking, Props: step.Props})
+			vertices = append(vertices, step.Dst)
+			src = step.Dst
+		}
+		return vertices, edges
+	}
+
+	func listValues(v *nebula.Value) []*nebula.Value {
+		if l := v.GetLVal(); l != nil {
+			return l.Values
+		}
+		return nil
+	}`)
+}
+
+// vertexType 路径和子图中点的类型，起点和终点相同时是具体的实体，否则是basepo.ITag
+func (g *Generator) vertexType(s *Struct) string {
+	if s.from == s.to {
+		return "*" + s.from
+	}
+	return "basepo.ITag"
+}
+
+// funcPath 为声明了from和to的边生成 <Edge>Path、<Edge>Subgraph 以及 ShortestPath、AllPaths、Subgraph。
+// 路径中的点按经过边的方向确定是起点实体还是终点实体，dir对应REVERSELY和BIDIRECT
+func (g *Generator) funcPath(s *Struct) {
+	if !s.isEdge || s.from == "" || s.to == "" {
+		return
+	}
+	from, to := g.findStruct(s.from), g.findStruct(s.to)
+	path := s.name + "Path"
+	g.Printlnf(`type ` + path + ` struct {`)
+	g.Printlnf(`	Vertices []` + g.vertexType(s))
+	g.Printlnf(`	Edges    []*` + s.name)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func new` + path + `(p *nebula.Path) *` + path + ` {`)
+	g.Printlnf(`	vertices, edges := pathSteps(p)`)
+	g.Printlnf(`	path := &` + path + `{}`)
+	if from == to {
+		g.Printlnf(`	for _, v := range vertices {`)
+		g.Printlnf(`		t := &` + from.name + `{}`)
+		g.Printlnf(`		t.BindVertex(v)`)
+		g.Printlnf(`		path.Vertices = append(path.Vertices, t)`)
+	} else {
+		g.Printlnf(`	for i, v := range vertices {`)
+		g.Printlnf(`		isFrom := len(edges) == 0 || edges[0].Type > 0`)
+		g.Printlnf(`		if i > 0 {`)
+		g.Printlnf(`			isFrom = edges[i-1].Type < 0`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`		if isFrom {`)
+		g.Printlnf(`			t := &` + from.name + `{}`)
+		g.Printlnf(`			t.BindVertex(v)`)
+		g.Printlnf(`			path.Vertices = append(path.Vertices, t)`)
+		g.Printlnf(`		} else {`)
+		g.Printlnf(`			t := &` + to.name + `{}`)
+		g.Printlnf(`			t.BindVertex(v)`)
+		g.Printlnf(`			path.Vertices = append(path.Vertices, t)`)
+		g.Printlnf(`		}`)
+	}
+	g.Printlnf(`	}`)
+	g.Printlnf(`	for _, e := range edges {`)
+	g.Printlnf(`		t := &` + s.name + `{}`)
+	g.Printlnf(`		t.BindEdge(e)`)
+	g.Printlnf(`		path.Edges = append(path.Edges, t)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return path`)
+	g.Printlnf(`}`)
+
+	g.funcFindPath(s, "ShortestPath", "FIND SHORTEST PATH")
+	g.funcFindPath(s, "AllPaths", "FIND ALL PATH")
+	g.funcSubgraph(s, from, to)
+}
+
+func (g *Generator) funcFindPath(s *Struct, method, find string) {
+	path := s.name + "Path"
+	g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session, src, dst int64, steps int, dir basepo.Direction) ([]*` + path + `, error) {`)
+	g.Printlnf(`	nql := "` + find + ` FROM " + strconv.FormatInt(src, 10) + " TO " + strconv.FormatInt(dst, 10) +`)
+	g.Printlnf(`		" OVER ` + s.nickname + `" + dir.Over() + " UPTO " + strconv.Itoa(steps) + " STEPS YIELD path AS p"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	paths := make([]*` + path + `, 0, result.GetRowSize())`)
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	g.Printlnf(`		if p := row.Values[0].GetPVal(); p != nil {`)
+	g.Printlnf(`			paths = append(paths, new` + path + `(p))`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return paths, nil`)
+	g.Printlnf(`}`)
+}
+
+// funcSubgraph GET SUBGRAPH WITH PROP，dir对应OUT、IN、BOTH；点按Tag的名字绑定成起点或者终点实体，其他Tag的点忽略
+func (g *Generator) funcSubgraph(s, from, to *Struct) {
+	subgraph := s.name + "Subgraph"
+	g.Printlnf(`type ` + subgraph + ` struct {`)
+	g.Printlnf(`	Vertices []` + g.vertexType(s))
+	g.Printlnf(`	Edges    []*` + s.name)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) Subgraph(session *nebula_go.Session, id int64, steps int, dir basepo.Direction) (*` + subgraph + `, error) {`)
+	g.Printlnf(`	nql := "GET SUBGRAPH WITH PROP " + strconv.Itoa(steps) + " STEPS FROM " + strconv.FormatInt(id, 10) +`)
+	g.Printlnf(`		" " + dir.Subgraph() + " ` + s.nickname + ` YIELD VERTICES AS nodes, EDGES AS relationships"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	subgraph := &` + subgraph + `{}`)
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	g.Printlnf(`		for _, v := range listValues(row.Values[0]) {`)
+	g.Printlnf(`			vertex := v.GetVVal()`)
+	g.Printlnf(`			if vertex == nil {`)
+	g.Printlnf(`				continue`)
+	g.Printlnf(`			}`)
+	g.Printlnf(`			for _, tag := range vertex.Tags {`)
+	targets := []*Struct{from}
+	if to != from {
+		targets = append(targets, to)
+	}
+	for _, t := range targets {
+		g.Printlnf(`				if string(tag.Name) == "` + t.nickname + `" {`)
+		g.Printlnf(`					t := &` + t.name + `{}`)
+		g.Printlnf(`					t.BindVertex(vertex)`)
+		g.Printlnf(`					subgraph.Vertices = append(subgraph.Vertices, t)`)
+		g.Printlnf(`					break`)
+		g.Printlnf(`				}`)
+	}
+	g.Printlnf(`			}`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		for _, v := range listValues(row.Values[1]) {`)
+	g.Printlnf(`			if e := v.GetEVal(); e != nil {`)
+	g.Printlnf(`				t := &` + s.name + `{}`)
+	g.Printlnf(`				t.BindEdge(e)`)
+	g.Printlnf(`				subgraph.Edges = append(subgraph.Edges, t)`)
+	g.Printlnf(`			}`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return subgraph, nil`)
+	g.Printlnf(`}`)
+}
@@ -0,0 +1,372 @@
+package main
+
+import "strings"
+
+// exportName 属性对应的Go标识符，例如 User + name -> UserName
+func (s *Struct) exportName(f *Field) string {
+	return s.name + strings.ToUpper(f.name[:1]) + f.name[1:]
+}
+
+// funcFieldVars 为每个属性生成查询用的 basepo.Field，例如 UserAge.Gt(18)
+func (g *Generator) funcFieldVars(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	g.Printlnf(`var (`)
+	if s.isTag {
+		g.Printlnf(`	` + s.exportName(IDFIELD) + ` = basepo.IdField`)
+	} else {
+		g.Printlnf(`	` + s.exportName(SRCFIELD) + ` = basepo.SrcField`)
+		g.Printlnf(`	` + s.exportName(DSTFIELD) + ` = basepo.DstField`)
+		g.Printlnf(`	` + s.exportName(RANKFIELD) + ` = basepo.RankField`)
+	}
+	for _, f := range s.fields {
+		g.Printlnf(`	` + s.exportName(&f) + ` = basepo.NewField("` + f.nickname + `")`)
+	}
+	g.Printlnf(`)`)
+}
+
+// matchReturns MATCH返回的列，列名和BindRecord一致
+func (s *Struct) matchReturns() string {
+	items := make([]string, 0)
+	if s.isTag {
+		items = append(items, "id(v) AS "+s.nickname+"_id")
+		for _, f := range s.fields {
+			items = append(items, "v."+s.nickname+"."+f.nickname+" AS "+s.nickname+"_"+f.nickname)
+		}
+	} else {
+		items = append(items, "e")
+		for _, f := range []*Field{SRCFIELD, DSTFIELD, RANKFIELD} {
+			items = append(items, f.nickname+"(e) AS "+s.nickname+"_"+f.nickname)
+		}
+		for _, f := range s.fields {
+			items = append(items, "e."+f.nickname+" AS "+s.nickname+"_"+f.nickname)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// lookupYields LOOKUP返回的列，列名和matchReturns一致
+func (s *Struct) lookupYields() string {
+	items := make([]string, 0)
+	if s.isTag {
+		items = append(items, "id(vertex) AS "+s.nickname+"_id")
+		for _, f := range s.fields {
+			items = append(items, "properties(vertex)."+f.nickname+" AS "+s.nickname+"_"+f.nickname)
+		}
+	} else {
+		items = append(items, "edge AS e")
+		for _, f := range []*Field{SRCFIELD, DSTFIELD, RANKFIELD} {
+			items = append(items, f.nickname+"(edge) AS "+s.nickname+"_"+f.nickname)
+		}
+		for _, f := range s.fields {
+			items = append(items, "properties(edge)."+f.nickname+" AS "+s.nickname+"_"+f.nickname)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func (s *Struct) matchPattern() string {
+	if s.isTag {
+		return "(v:" + s.nickname + ")"
+	}
+	return "()-[e:" + s.nickname + "]->()"
+}
+
+// funcQuery 生成 UserQuery() 以及执行查询的 Find
+func (g *Generator) funcQuery(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	g.Printlnf(`func ` + s.name + `Query() *basepo.Query {`)
+	if s.isTag {
+		g.Printlnf(`	return basepo.NewTagQuery("` + s.nickname + `")`)
+	} else {
+		g.Printlnf(`	return basepo.NewEdgeQuery("` + s.nickname + `")`)
+	}
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) Find(session *nebula_go.Session, ms *` + s.name + `List, q *basepo.Query) error {`)
+	g.Printlnf(`	if err := m.CheckFields(q.OrderFields()...); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	nql := q.MatchNQL("` + s.matchPattern() + `", "` + s.matchReturns() + `")`)
+	g.Printlnf(`	if q.IsLookup() {`)
+	g.Printlnf(`		if err := m.CheckLookup(q); err != nil {`)
+	g.Printlnf(`			return err`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		nql = q.LookupNQL("` + s.lookupYields() + `")`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	if s.isTag {
+		g.Printlnf(`	ms.BindResult(result)`)
+	} else {
+		g.Printlnf(`	ms.BindEdges(result, "e")`)
+	}
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// conditionFields 可以作为条件的字段：属性加上点的id或者边的起点、终点和rank
+func (s *Struct) conditionFields() []Field {
+	fields := make([]Field, 0, len(s.fields)+3)
+	if s.isTag {
+		fields = append(fields, *IDFIELD)
+	} else if s.isEdge {
+		fields = append(fields, *SRCFIELD, *DSTFIELD, *RANKFIELD)
+	}
+	return append(fields, s.fields...)
+}
+
+// funcFieldsStruct 生成 UserFields.Name 形式的字段名，代替手写的字符串
+func (g *Generator) funcFieldsStruct(s *Struct) {
+	fields := s.conditionFields()
+	g.Printlnf(`var ` + s.name + `Fields = struct {`)
+	for _, f := range fields {
+		g.Printlnf(`	` + strings.TrimPrefix(s.exportName(&f), s.name) + ` string`)
+	}
+	g.Printf(`}{`)
+	for _, f := range fields {
+		g.Printf(`"` + f.nickname + `",`)
+	}
+	g.Printlnf(`}`)
+}
+
+// funcCheckFields 字段名拼写错误时返回 basepo.ErrUnknownField
+func (g *Generator) funcCheckFields(s *Struct) {
+	g.Printlnf(`func (m *` + s.name + `) CheckFields(fields ...string) error {`)
+	g.Printlnf(`	for _, f := range fields {`)
+	g.Printlnf(`		switch f {`)
+	names := make([]string, 0)
+	for _, f := range s.conditionFields() {
+		names = append(names, `"`+f.nickname+`"`)
+	}
+	if len(names) > 0 {
+		g.Printlnf(`		case ` + strings.Join(names, ", ") + `:`)
+	}
+	g.Printlnf(`		default:`)
+	g.Printlnf(`			return fmt.Errorf("%%w: ` + s.nickname + `.%%s", basepo.ErrUnknownField, f)`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcCheckProps 写入的字段只能是属性，id、src、dst、rank返回 basepo.ErrNotProperty
+func (g *Generator) funcCheckProps(s *Struct) {
+	g.Printlnf(`func (m *` + s.name + `) CheckProps(fields ...string) error {`)
+	g.Printlnf(`	if err := m.CheckFields(fields...); err != nil {`)
+	g.Printlnf(`		return err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	for _, f := range fields {`)
+	g.Printlnf(`		switch f {`)
+	names := make([]string, 0)
+	for _, f := range s.fields {
+		names = append(names, `"`+f.nickname+`"`)
+	}
+	if len(names) > 0 {
+		g.Printlnf(`		case ` + strings.Join(names, ", ") + `:`)
+	}
+	g.Printlnf(`		default:`)
+	g.Printlnf(`			return fmt.Errorf("%%w: ` + s.nickname + `.%%s", basepo.ErrNotProperty, f)`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// mustCheckFields 和生成的方法一样，出错时panic
+func (g *Generator) mustCheckFields() {
+	g.Printlnf(`if err := m.CheckFields(fields...); err != nil {`)
+	g.Printlnf(`	panic(err)`)
+	g.Printlnf(`}`)
+}
+
+// funcDeleteWhere 先通过MATCH或LOOKUP查出满足条件的点或边，再按basepo.DefaultBatchSize分批删除。
+// Tag只删除当前的Tag（DELETE TAG），和RemoveById一致；cond为nil时需要显式设置DeleteOptions.All
+func (g *Generator) funcDeleteWhere(s *Struct) {
+	if !s.isTag && !s.isEdge {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) DeleteWhere(session *nebula_go.Session, cond basepo.Cond, opts basepo.DeleteOptions) (int, error) {`)
+	g.Printlnf(`if cond == nil && !opts.All {`)
+	g.Printlnf(`	return 0, fmt.Errorf("%%w: delete all ` + s.nickname + ` needs DeleteOptions.All", basepo.ErrNoCondition)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`q := ` + s.name + `Query().Where(cond)`)
+	g.Printlnf(`if opts.Lookup {`)
+	g.Printlnf(`	q.UseLookup()`)
+	g.Printlnf(`	if err := m.CheckLookup(q); err != nil {`)
+	g.Printlnf(`		return 0, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if opts.MaxRows > 0 {`)
+	g.Printlnf(`	q.Limit(int64(opts.MaxRows) + 1)`)
+	g.Printlnf(`}`)
+	if s.isTag {
+		g.Printlnf(`nql := q.MatchNQL("` + s.matchPattern() + `", "id(v) AS ` + s.nickname + `_id")`)
+		g.Printlnf(`if q.IsLookup() {`)
+		g.Printlnf(`	nql = q.LookupNQL("id(vertex) AS ` + s.nickname + `_id")`)
+		g.Printlnf(`}`)
+	} else {
+		g.Printlnf(`nql := q.MatchNQL("` + s.matchPattern() + `", "src(e) AS ` + s.nickname + `_src,dst(e) AS ` + s.nickname + `_dst,rank(e) AS ` + s.nickname + `_rank")`)
+		g.Printlnf(`if q.IsLookup() {`)
+		g.Printlnf(`	nql = q.LookupNQL("src(edge) AS ` + s.nickname + `_src,dst(edge) AS ` + s.nickname + `_dst,rank(edge) AS ` + s.nickname + `_rank")`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`result, err := session.Execute(nql)`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return 0, err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`	return 0, err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`keys := make([]string, 0, result.GetRowSize())`)
+	g.Printlnf(`for _, row := range result.GetRows() {`)
+	if s.isTag {
+		g.Printlnf(`	keys = append(keys, strconv.FormatInt(row.Values[0].GetIVal(), 10))`)
+	} else {
+		g.Printlnf(`	keys = append(keys, strconv.FormatInt(row.Values[0].GetIVal(), 10)+"->"+strconv.FormatInt(row.Values[1].GetIVal(), 10)+"@"+strconv.FormatInt(row.Values[2].GetIVal(), 10))`)
+	}
+	g.Printlnf(`}`)
+	g.Printlnf(`if opts.MaxRows > 0 && len(keys) > opts.MaxRows {`)
+	g.Printlnf(`	return len(keys), fmt.Errorf("%%w: ` + s.nickname + ` matched more than %%d", basepo.ErrTooManyRows, opts.MaxRows)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if opts.DryRun {`)
+	g.Printlnf(`	return len(keys), nil`)
+	g.Printlnf(`}`)
+	g.Printlnf(`deleted := 0`)
+	g.Printlnf(`for _, chunk := range basepo.Chunks(len(keys), 0) {`)
+	if s.isTag {
+		g.Printlnf(`	nql := "DELETE TAG " + m.TagName() + " FROM " + strings.Join(keys[chunk.Start:chunk.End], ",")`)
+	} else {
+		g.Printlnf(`	nql := "DELETE EDGE " + m.EdgeName() + " " + strings.Join(keys[chunk.Start:chunk.End], ",")`)
+	}
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err == nil {`)
+	g.Printlnf(`		err = resultError(nql, result)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return deleted, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	deleted += chunk.End - chunk.Start`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return deleted, nil`)
+	g.Printlnf("}")
+}
+
+// funcFieldValue 按字段名取值，生成分页的cursor时使用
+func (g *Generator) funcFieldValue(s *Struct) {
+	g.Printlnf(`func (m *` + s.name + `) fieldValue(field string) interface{} {`)
+	g.Printlnf(`	switch field {`)
+	for _, f := range s.fields {
+		g.Printlnf(`	case "` + f.nickname + `":`)
+		g.Printlnf(`		return m.` + f.name)
+	}
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcPage 键集分页：按排序字段和点的id从cursor之后取size个，返回下一页的cursor，没有下一页时为空。
+// 和List的SKIP不同，翻页的代价不随页数增加，并发写入时也不会重复或者遗漏
+func (g *Generator) funcPage(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.funcFieldValue(s)
+	g.Printlnf(`func (m *` + s.name + `) Page(session *nebula_go.Session, q *basepo.Query, size int64, cursor string) (` + s.name + `List, string, error) {`)
+	g.Printlnf(`	if size <= 0 {`)
+	g.Printlnf(`		return nil, "", fmt.Errorf("ngorm: page size %%d must be positive", size)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := m.CheckFields(q.OrderFields()...); err != nil {`)
+	g.Printlnf(`		return nil, "", err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	nql, err := q.KeysetNQL("` + s.matchPattern() + `", "` + s.matchReturns() + `", cursor, size)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, "", err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, "", err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return nil, "", err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	ms := make(` + s.name + `List, 0, result.GetRowSize())`)
+	g.Printlnf(`	ms.BindResult(result)`)
+	g.Printlnf(`	if int64(len(ms)) < size {`)
+	g.Printlnf(`		return ms, "", nil`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	last := ms[len(ms)-1]`)
+	g.Printlnf(`	return ms, q.NextCursor(last.fieldValue, last.Id()), nil`)
+	g.Printlnf(`}`)
+}
+
+// funcIter 按basepo.DefaultBatchSize分页遍历查询的结果，内存中只保留一页
+func (g *Generator) funcIter(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	iter := s.name + `Iter`
+	g.Printlnf(`type ` + iter + ` struct {`)
+	g.Printlnf(`	session *nebula_go.Session`)
+	g.Printlnf(`	q       *basepo.Query`)
+	g.Printlnf(`	cursor  string`)
+	g.Printlnf(`	page    ` + s.name + `List`)
+	g.Printlnf(`	pos     int`)
+	g.Printlnf(`	done    bool`)
+	g.Printlnf(`	err     error`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) Iter(session *nebula_go.Session, q *basepo.Query) *` + iter + ` {`)
+	g.Printlnf(`	return &` + iter + `{session: session, q: q}`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (it *` + iter + `) Next() bool {`)
+	g.Printlnf(`	if it.err != nil {`)
+	g.Printlnf(`		return false`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if it.pos < len(it.page) {`)
+	g.Printlnf(`		it.pos++`)
+	g.Printlnf(`		return true`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if it.done {`)
+	g.Printlnf(`		return false`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	page, cursor, err := (&` + s.name + `{}).Page(it.session, it.q, basepo.DefaultBatchSize, it.cursor)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		it.err = err`)
+	g.Printlnf(`		return false`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	it.page, it.cursor, it.pos = page, cursor, 0`)
+	g.Printlnf(`	it.done = cursor == ""`)
+	g.Printlnf(`	if len(page) == 0 {`)
+	g.Printlnf(`		return false`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	it.pos = 1`)
+	g.Printlnf(`	return true`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (it *` + iter + `) Scan(m *` + s.name + `) error {`)
+	g.Printlnf(`	if it.pos == 0 || it.pos > len(it.page) {`)
+	g.Printlnf(`		return fmt.Errorf("ngorm: Scan called without Next")`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	*m = *it.page[it.pos-1]`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (it *` + iter + `) Err() error {`)
+	g.Printlnf(`	return it.err`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (it *` + iter + `) Close() error {`)
+	g.Printlnf(`	it.page, it.pos, it.done = nil, 0, true`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"go/ast"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ngormOptions 解析 `ngorm:"from=User,to=Group"` 形式的标签，没有值的选项对应空字符串
+func ngormOptions(tag *ast.BasicLit) map[string]string {
+	options := make(map[string]string)
+	if tag == nil {
+		return options
+	}
+	value, ok := reflect.StructTag(strings.Trim(tag.Value, "`")).Lookup("ngorm")
+	if !ok {
+		return options
+	}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) == 2 {
+			options[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		} else {
+			options[kv[0]] = ""
+		}
+	}
+	return options
+}
+
+// Relation 预加载字段，`ngorm:"rel=UserGroup"`，元素类型是边时加载边，否则加载另一端的实体
+type Relation struct {
+	field   string // 字段名
+	elem    string // 元素类型
+	edge    string // 边的结构体名
+	reverse bool   // 起点和终点相同时，通过reverse选择反向
+}
+
+func (s *Struct) parseRelation(field *ast.Field, arr *ast.ArrayType) {
+	options := ngormOptions(field.Tag)
+	edge, ok := options["rel"]
+	if !ok {
+		return
+	}
+	star, ok := arr.Elt.(*ast.StarExpr)
+	if !ok {
+		log.Fatalf("%s: relation field must be a slice of pointers", s.name)
+	}
+	elem, ok := star.X.(*ast.Ident)
+	if !ok {
+		log.Fatalf("%s: relation field must be a slice of pointers", s.name)
+	}
+	_, reverse := options["reverse"]
+	for _, name := range field.Names {
+		s.rels = append(s.rels, Relation{field: name.Name, elem: elem.Name, edge: edge, reverse: reverse})
+	}
+}
+
+// relationNames 去重后的预加载关系，保持声明顺序
+func (s *Struct) relationNames() []string {
+	names := make([]string, 0)
+	exist := make(map[string]bool)
+	for _, r := range s.rels {
+		if !exist[r.edge] {
+			exist[r.edge] = true
+			names = append(names, r.edge)
+		}
+	}
+	return names
+}
+
+// reversely 当前实体是否位于边的终点一侧
+func (s *Struct) reversely(e *Struct) bool {
+	if e.from == e.to {
+		for _, r := range s.rels {
+			if r.edge == e.name && r.reverse {
+				return true
+			}
+		}
+		return false
+	}
+	return e.to == s.name
+}
+
+func (s *Struct) parseEdgeOptions(tag *ast.BasicLit) {
+	options := ngormOptions(tag)
+	s.from = options["from"]
+	s.to = options["to"]
+	s.onDelete = options["onDelete"]
+	if s.onDelete != "" && (s.from == "" || s.to == "") {
+		log.Fatalf("edge %s: onDelete=%s needs from and to, otherwise Delete removes the edge WITH EDGE", s.name, s.onDelete)
+	}
+	switch s.onDelete {
+	case "":
+		s.onDelete = "detach"
+	case "detach", "cascade", "restrict":
+	default:
+		log.Fatalf("edge %s: unknown onDelete policy %s", s.name, s.onDelete)
+	}
+}
+
+func (g *Generator) findStruct(name string) *Struct {
+	for i := range g.Structs {
+		if g.Structs[i].name == name {
+			return &g.Structs[i]
+		}
+	}
+	return nil
+}
+
+// checkRelations 边声明的起点和终点必须是同一个包里的Tag
+func (g *Generator) checkRelations() {
+	for _, s := range g.Structs {
+		if !s.isEdge {
+			continue
+		}
+		for _, name := range []string{s.from, s.to} {
+			if name == "" {
+				continue
+			}
+			if t := g.findStruct(name); t == nil || !t.isTag {
+				log.Fatalf("edge %s: %s is not a tag struct", s.name, name)
+			}
+		}
+	}
+	for _, s := range g.Structs {
+		for _, r := range s.rels {
+			e := g.findStruct(r.edge)
+			if e == nil || !e.isEdge || e.from == "" || e.to == "" {
+				log.Fatalf("%s.%s: %s is not an edge with declared from and to", s.name, r.field, r.edge)
+			}
+			if e.from != s.name && e.to != s.name {
+				log.Fatalf("%s.%s: edge %s does not connect %s", s.name, r.field, r.edge, s.name)
+			}
+			if e.from == e.to && r.reverse != s.reversely(e) {
+				log.Fatalf("%s.%s: all relations on %s must use the same direction", s.name, r.field, r.edge)
+			}
+			other := e.to
+			if s.reversely(e) {
+				other = e.from
+			}
+			if r.elem != e.name && r.elem != other {
+				log.Fatalf("%s.%s: element type must be %s or %s", s.name, r.field, e.name, other)
+			}
+		}
+	}
+	g.checkNeighbors()
+}
+
+// neighbor Tag上沿着一种边走一步的方法
+type neighbor struct {
+	method  string
+	edge    *Struct
+	dir     string // 空为正向，" REVERSELY"为反向
+	targets *Struct
+}
+
+// plural 方法名的复数形式，已经以s结尾的不再追加
+func plural(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name
+	}
+	return name + "s"
+}
+
+// neighbors 起点上生成 <Edge>s 返回终点，终点上生成 <From>s 反向返回起点；
+// 起点和终点相同的边反向方法是 Reverse<Edge>s，不会和正向方法或者实体名重复
+func (g *Generator) neighbors(s *Struct) []neighbor {
+	ns := make([]neighbor, 0)
+	if !s.isTag {
+		return ns
+	}
+	for i := range g.Structs {
+		e := &g.Structs[i]
+		if !e.isEdge || e.from == "" || e.to == "" {
+			continue
+		}
+		if e.from == s.name {
+			ns = append(ns, neighbor{method: plural(e.name), edge: e, targets: g.findStruct(e.to)})
+		}
+		if e.to == s.name {
+			method := plural(e.from)
+			if e.from == e.to {
+				method = "Reverse" + plural(e.name)
+			}
+			ns = append(ns, neighbor{method: method, edge: e, dir: " REVERSELY", targets: g.findStruct(e.from)})
+		}
+	}
+	return ns
+}
+
+// tagMethods Tag上生成的其他方法和嵌入的basepo.Tag的方法，邻居方法不能和它们重名
+func (s *Struct) tagMethods() map[string]bool {
+	methods := make(map[string]bool)
+	for _, name := range []string{
+		"GenId", "SetId", "Id", "Id2", "SetUnloaded", "MarkLoaded", "Unloaded", "CheckLoaded",
+		"AllFields", "AllFieldsWithId", "TagName", "NqlNameValues", "NqlValues", "NqlNames", "NqlBind",
+		"Create", "Insert", "InsertWith", "InsertBatch", "Update", "Upsert", "UpdateWhen", "UpdateExpr",
+		"BindRecord", "BindVertex", "BindTag", "ConditionItem", "BindOne", "One", "OneWith", "List", "ListWith", "CheckFields", "CheckProps", "CheckLookup", "Find",
+		"RemoveById", "RemoveTag", "RemoveVertex", "RemoveWithEdge", "DeleteWhere", "Delete", "Count", "Exists",
+		"Page", "Iter", "Get", "GetMany", "Traverse", "Preload",
+	} {
+		methods[name] = true
+	}
+	for _, f := range s.fields {
+		name := strings.TrimPrefix(s.exportName(&f), s.name)
+		methods["GroupBy"+name] = true
+		if f.isNumeric() {
+			for _, fn := range []string{"Sum", "Avg", "Min", "Max"} {
+				methods[fn+name] = true
+			}
+		}
+		if f.isIndex {
+			method := "LookupBy"
+			for _, field := range s.indexFields(&f) {
+				method += strings.TrimPrefix(s.exportName(field), s.name)
+				methods[method] = true
+			}
+		}
+	}
+	return methods
+}
+
+// checkNeighbors 邻居方法不能和字段、其他生成的方法以及其他边的邻居方法重名
+func (g *Generator) checkNeighbors() {
+	for i := range g.Structs {
+		s := &g.Structs[i]
+		if !s.isTag {
+			continue
+		}
+		members := make(map[string]bool)
+		for _, name := range s.members {
+			members[name] = true
+		}
+		methods := s.tagMethods()
+		declared := make(map[string]string)
+		for _, n := range g.neighbors(s) {
+			if members[n.method] {
+				log.Fatalf("%s: method %s for edge %s has the same name as field %s.%s", s.name, n.method, n.edge.name, s.name, n.method)
+			}
+			if methods[n.method] {
+				log.Fatalf("%s: method %s for edge %s conflicts with generated method %s.%s", s.name, n.method, n.edge.name, s.name, n.method)
+			}
+			if other, ok := declared[n.method]; ok {
+				log.Fatalf("%s: edges %s and %s both generate method %s.%s", s.name, other, n.edge.name, s.name, n.method)
+			}
+			declared[n.method] = n.edge.name
+		}
+	}
+	for _, e := range g.Structs {
+		if !e.isEdge || e.from == "" || e.to == "" {
+			continue
+		}
+		for _, name := range e.members {
+			if name == "Out" || name == "In" || name == "Both" {
+				log.Fatalf("%s.%s: field has the same name as generated method %s.%s", e.name, name, e.name, name)
+			}
+		}
+	}
+}
+
+// funcNeighbors 为Tag生成沿着已声明端点的边遍历的方法，方法名见neighbors
+func (g *Generator) funcNeighbors(s *Struct) {
+	for _, n := range g.neighbors(s) {
+		g.funcNeighbor(s, n.edge, n.method, n.dir, false, n.targets)
+	}
+}
+
+// funcDirections 为声明了端点的边生成按方向返回另一端实体的方法：
+// Out 从起点到终点，In 反向（REVERSELY），Both 两个方向（BIDIRECT）。
+// 起点和终点不是同一个实体时Both返回basepo.ITag
+func (g *Generator) funcDirections(e *Struct) {
+	if !e.isEdge || e.from == "" || e.to == "" {
+		return
+	}
+	from, to := g.findStruct(e.from), g.findStruct(e.to)
+	g.funcNeighbor(e, e, "Out", "", true, to)
+	g.funcNeighbor(e, e, "In", " REVERSELY", true, from)
+	if from == to {
+		g.funcNeighbor(e, e, "Both", " BIDIRECT", true, from)
+	} else {
+		g.funcNeighbor(e, e, "Both", " BIDIRECT", true, from, to)
+	}
+}
+
+// funcNeighbor 通过GO沿着边走一步，id($$)是另一端的点，再FETCH另一端的实体。
+// byIds为true时从参数ids出发，否则从m.Id()出发
+func (g *Generator) funcNeighbor(s, e *Struct, method, dir string, byIds bool, targets ...*Struct) {
+	result := "basepo.ITag"
+	if len(targets) == 1 {
+		result = "*" + targets[0].name
+	}
+	tags := make([]string, 0, len(targets))
+	for _, t := range targets {
+		tags = append(tags, t.nickname)
+	}
+	if byIds {
+		g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session, ids ...int64) ([]` + result + `, error) {`)
+		g.Printlnf(`	if len(ids) == 0 {`)
+		g.Printlnf(`		return nil, nil`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	vids := make([]string, 0, len(ids))`)
+		g.Printlnf(`	for _, id := range ids {`)
+		g.Printlnf(`		vids = append(vids, strconv.FormatInt(id, 10))`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	nql := "GO FROM " + strings.Join(vids, ",") + " OVER ` + e.nickname + dir + ` YIELD DISTINCT id($$) AS id" +`)
+	} else {
+		g.Printlnf(`func (m *` + s.name + `) ` + method + `(session *nebula_go.Session) ([]` + result + `, error) {`)
+		g.Printlnf(`	nql := "GO FROM " + strconv.FormatInt(m.Id(), 10) + " OVER ` + e.nickname + dir + ` YIELD DISTINCT id($$) AS id" +`)
+	}
+	g.Printlnf(`		" | FETCH PROP ON ` + strings.Join(tags, ",") + ` $-.id YIELD vertex AS v"`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	ms := make([]` + result + `, 0, result.GetRowSize())`)
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	if len(targets) == 1 {
+		g.Printlnf(`		t := &` + targets[0].name + `{}`)
+		g.Printlnf(`		t.BindVertex(row.Values[0].GetVVal())`)
+		g.Printlnf(`		ms = append(ms, t)`)
+	} else {
+		g.Printlnf(`		v := row.Values[0].GetVVal()`)
+		g.Printlnf(`		for _, tag := range v.Tags {`)
+		for _, t := range targets {
+			g.Printlnf(`			if string(tag.Name) == "` + t.nickname + `" {`)
+			g.Printlnf(`				t := &` + t.name + `{}`)
+			g.Printlnf(`				t.BindVertex(v)`)
+			g.Printlnf(`				ms = append(ms, t)`)
+			g.Printlnf(`				break`)
+			g.Printlnf(`			}`)
+		}
+		g.Printlnf(`		}`)
+	}
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return ms, nil`)
+	g.Printlnf(`}`)
+}
+
+// funcPreload 每个关系通过一次MATCH批量查询所有实体的另一端，再挂到对应字段上，
+// 只有字段的元素类型是边时才返回边
+func (g *Generator) funcPreload(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.Printlnf(`func (m *` + s.name + `) Preload(session *nebula_go.Session, relations ...basepo.IEdge) error {`)
+	g.Printlnf(`	ms := ` + s.name + `List{m}`)
+	g.Printlnf(`	return ms.Preload(session, relations...)`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (ms *` + s.name + `List) Preload(session *nebula_go.Session, relations ...basepo.IEdge) error {`)
+	if len(s.rels) == 0 {
+		g.Printlnf(`	if len(relations) > 0 {`)
+		g.Printlnf(`		return fmt.Errorf("` + s.name + `: unknown relation %%s", relations[0].EdgeName())`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	return nil`)
+		g.Printlnf(`}`)
+		return
+	}
+	g.Printlnf(`	if len(*ms) == 0 || len(relations) == 0 {`)
+	g.Printlnf(`		return nil`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	ids := make([]string, 0, len(*ms))`)
+	g.Printlnf(`	index := make(map[int64]*` + s.name + `, len(*ms))`)
+	g.Printlnf(`	for _, m := range *ms {`)
+	g.Printlnf(`		ids = append(ids, strconv.FormatInt(m.Id(), 10))`)
+	g.Printlnf(`		index[m.Id()] = m`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	for _, relation := range relations {`)
+	for i, name := range s.relationNames() {
+		e := g.findStruct(name)
+		other := g.findStruct(e.to)
+		pattern := "(a:" + s.nickname + ")-[e:" + e.nickname + "]->(b:" + other.nickname + ")"
+		if s.reversely(e) {
+			other = g.findStruct(e.from)
+			pattern = "(a:" + s.nickname + ")<-[e:" + e.nickname + "]-(b:" + other.nickname + ")"
+		}
+		// 只返回字段需要的列
+		returns, edgeCol, vertexCol := "id(a) AS src", 0, 0
+		for _, r := range s.rels {
+			if r.edge != name {
+				continue
+			}
+			if r.elem == e.name && edgeCol == 0 {
+				returns += ", e"
+				edgeCol = strings.Count(returns, ",")
+			} else if r.elem != e.name && vertexCol == 0 {
+				returns += ", b"
+				vertexCol = strings.Count(returns, ",")
+			}
+		}
+		if i != 0 {
+			g.Printf(`else `)
+		}
+		g.Printlnf(`if relation.EdgeName() == "` + e.nickname + `" {`)
+		g.Printlnf(`nql := "MATCH ` + pattern + ` WHERE id(a) IN [" + strings.Join(ids, ",") + "] RETURN ` + returns + `"`)
+		g.Printlnf(`result, err := session.Execute(nql)`)
+		g.Printlnf(`if err != nil {`)
+		g.Printlnf(`	return err`)
+		g.Printlnf(`}`)
+		g.Printlnf(`if err := resultError(nql, result); err != nil {`)
+		g.Printlnf(`	return err`)
+		g.Printlnf(`}`)
+		g.Printlnf(`for _, m := range *ms {`)
+		for _, r := range s.rels {
+			if r.edge == name {
+				g.Printlnf(`	m.` + r.field + ` = nil`)
+			}
+		}
+		g.Printlnf(`}`)
+		g.Printlnf(`for _, row := range result.GetRows() {`)
+		g.Printlnf(`	m := index[row.Values[0].GetIVal()]`)
+		g.Printlnf(`	if m == nil {`)
+		g.Printlnf(`		continue`)
+		g.Printlnf(`	}`)
+		for _, r := range s.rels {
+			if r.edge != name {
+				continue
+			}
+			if r.elem == e.name {
+				g.Printlnf(`	{`)
+				g.Printlnf(`		e := &` + e.name + `{}`)
+				g.Printlnf(`		e.BindEdge(row.Values[` + strconv.Itoa(edgeCol) + `].GetEVal())`)
+				g.Printlnf(`		m.` + r.field + ` = append(m.` + r.field + `, e)`)
+				g.Printlnf(`	}`)
+			} else {
+				g.Printlnf(`	{`)
+				g.Printlnf(`		b := &` + other.name + `{}`)
+				g.Printlnf(`		b.BindVertex(row.Values[` + strconv.Itoa(vertexCol) + `].GetVVal())`)
+				g.Printlnf(`		m.` + r.field + ` = append(m.` + r.field + `, b)`)
+				g.Printlnf(`	}`)
+			}
+		}
+		g.Printlnf(`}`)
+		g.Printf(`}`)
+	}
+	g.Printlnf(` else {`)
+	g.Printlnf(`		return fmt.Errorf("` + s.name + `: unknown relation %%s", relation.EdgeName())`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcDelete 按边上声明的删除策略删除点，分两步：
+// planDelete 沿着cascade的边收集所有要删除的点并检查每个点的restrict边，有一个不满足就不删除任何东西；
+// 全部通过后 deleteOne 依次删除每个点detach和cascade的边；点上还有其他Tag时只删除当前Tag，
+// 否则删除剩下的边后删除点，所有删除的边都按类型计入RemoveReport
+func (g *Generator) funcDelete(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	type side struct {
+		edge      *Struct
+		reversely bool
+	}
+	sides := make([]side, 0)
+	for i := range g.Structs {
+		e := &g.Structs[i]
+		if !e.isEdge || e.from == "" || e.to == "" {
+			continue
+		}
+		if e.from == s.name {
+			sides = append(sides, side{edge: e})
+		}
+		if e.to == s.name {
+			sides = append(sides, side{edge: e, reversely: true})
+		}
+	}
+	over := func(sd side) string {
+		if sd.reversely {
+			return sd.edge.nickname + " REVERSELY"
+		}
+		return sd.edge.nickname
+	}
+
+	g.Printlnf(`func (m *` + s.name + `) Delete(session *nebula_go.Session) (*basepo.RemoveReport, error) {`)
+	g.Printlnf(`plan := basepo.NewDeletePlan()`)
+	g.Printlnf(`if err := m.planDelete(session, plan); err != nil {`)
+	g.Printlnf(`	return basepo.NewRemoveReport(), err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`return plan.Run()`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) planDelete(session *nebula_go.Session, plan *basepo.DeletePlan) error {`)
+	g.Printlnf(`if !plan.Visit(m.Id()) {`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+	needId := false
+	for _, sd := range sides {
+		if sd.edge.onDelete == "restrict" || (sd.edge.onDelete == "cascade" && !sd.reversely) {
+			needId = true
+		}
+	}
+	if needId {
+		g.Printlnf(`id := strconv.FormatInt(m.Id(), 10)`)
+	}
+	for _, sd := range sides {
+		if sd.edge.onDelete != "restrict" {
+			continue
+		}
+		g.Printlnf(`{`)
+		g.Printlnf(`	nql := "GO FROM " + id + " OVER ` + over(sd) + ` YIELD src(edge) AS src | LIMIT 1"`)
+		g.Printlnf(`	result, err := session.Execute(nql)`)
+		g.Printlnf(`	if err != nil {`)
+		g.Printlnf(`		return err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+		g.Printlnf(`		return err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	if result.GetRowSize() > 0 {`)
+		g.Printlnf(`		return fmt.Errorf("%%w: ` + s.nickname + ` %%s has ` + sd.edge.nickname + `", basepo.ErrRestricted, id)`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`plan.Add(func(report *basepo.RemoveReport) error {`)
+	g.Printlnf(`	return m.deleteOne(session, report)`)
+	g.Printlnf(`})`)
+	for _, sd := range sides {
+		if sd.edge.onDelete != "cascade" || sd.reversely {
+			continue
+		}
+		target := g.findStruct(sd.edge.to)
+		g.Printlnf(`{`)
+		g.Printlnf(`	nql := "GO FROM " + id + " OVER ` + over(sd) + ` YIELD dst(edge) AS dst"`)
+		g.Printlnf(`	result, err := session.Execute(nql)`)
+		g.Printlnf(`	if err != nil {`)
+		g.Printlnf(`		return err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+		g.Printlnf(`		return err`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`	for _, row := range result.GetRows() {`)
+		g.Printlnf(`		t := &` + target.name + `{}`)
+		g.initTagVar(target, "t")
+		g.Printlnf(`		t.SetId(row.Values[0].GetIVal())`)
+		g.Printlnf(`		if err := t.planDelete(session, plan); err != nil {`)
+		g.Printlnf(`			return err`)
+		g.Printlnf(`		}`)
+		g.Printlnf(`	}`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`return nil`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) deleteOne(session *nebula_go.Session, report *basepo.RemoveReport) error {`)
+	g.Printlnf(`id := strconv.FormatInt(m.Id(), 10)`)
+	for _, sd := range sides {
+		if sd.edge.onDelete == "restrict" {
+			continue
+		}
+		g.Printlnf(`if err := deleteEdges(session, id, "` + over(sd) + `", report); err != nil {`)
+		g.Printlnf(`	return err`)
+		g.Printlnf(`}`)
+	}
+	g.Printlnf(`others, err := otherTags(session, id, m.TagName())`)
+	g.Printlnf(`if err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if others {`)
+	g.Printlnf(`	return m.RemoveTag(session)`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := deleteEdges(session, id, "* BIDIRECT", report); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`if err := m.RemoveVertex(session); err != nil {`)
+	g.Printlnf(`	return err`)
+	g.Printlnf(`}`)
+	g.Printlnf(`report.Vertices = append(report.Vertices, m.Id())`)
+	g.Printlnf(`return nil`)
+	g.Printlnf(`}`)
+}
+
+// funcTraverse 生成 UserTraverse(ids...) 以及执行遍历的 Traverse，终点按VID去重，
+// WithDepth 时通过depths返回每个终点的最短步数
+func (g *Generator) funcTraverse(s *Struct) {
+	if !s.isTag {
+		return
+	}
+	g.Printlnf(`func ` + s.name + `Traverse(ids ...int64) *basepo.Traversal {`)
+	g.Printlnf(`	return basepo.NewTraversal("` + s.nickname + `", ids...)`)
+	g.Printlnf(`}`)
+
+	g.Printlnf(`func (m *` + s.name + `) Traverse(session *nebula_go.Session, t *basepo.Traversal) (` + s.name + `List, map[int64]int, error) {`)
+	g.Printlnf(`	nql := t.MatchNQL("` + s.nickname + `")`)
+	g.Printlnf(`	result, err := session.Execute(nql)`)
+	g.Printlnf(`	if err != nil {`)
+	g.Printlnf(`		return nil, nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	if err := resultError(nql, result); err != nil {`)
+	g.Printlnf(`		return nil, nil, err`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	ms := make(` + s.name + `List, 0, result.GetRowSize())`)
+	g.Printlnf(`	seen := make(map[int64]bool, result.GetRowSize())`)
+	g.Printlnf(`	var depths map[int64]int`)
+	g.Printlnf(`	if t.HasDepth() {`)
+	g.Printlnf(`		depths = make(map[int64]int, result.GetRowSize())`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	g.Printlnf(`		v := row.Values[0].GetVVal()`)
+	g.Printlnf(`		if v == nil {`)
+	g.Printlnf(`			continue`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		id := v.Vid.GetIVal()`)
+	g.Printlnf(`		if depths != nil {`)
+	g.Printlnf(`			depth := int(row.Values[1].GetIVal())`)
+	g.Printlnf(`			if old, ok := depths[id]; !ok || depth < old {`)
+	g.Printlnf(`				depths[id] = depth`)
+	g.Printlnf(`			}`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		if seen[id] {`)
+	g.Printlnf(`			continue`)
+	g.Printlnf(`		}`)
+	g.Printlnf(`		seen[id] = true`)
+	g.Printlnf(`		n := &` + s.name + `{}`)
+	g.Printlnf(`		n.BindVertex(v)`)
+	g.Printlnf(`		ms = append(ms, n)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return ms, depths, nil`)
+	g.Printlnf(`}`)
+}
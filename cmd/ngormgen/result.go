@@ -0,0 +1,108 @@
+package main
+
+import (
+	"go/ast"
+	"log"
+	"strings"
+)
+
+// Column 结果结构体中的一列，按别名从结果中绑定，ngorm:"alias=u"，默认是小写的字段名
+type Column struct {
+	name   string
+	alias  string
+	typ    string
+	entity bool // *User或者*UserGroup这样的实体指针
+}
+
+func (s *Struct) parseColumns(st *ast.StructType) {
+	s.fields = nil
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 {
+			continue
+		}
+		var typ string
+		entity := false
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			typ = t.Name
+		case *ast.StarExpr:
+			if id, ok := t.X.(*ast.Ident); ok {
+				typ, entity = id.Name, true
+			}
+		}
+		if typ == "" {
+			log.Fatalf("result %s: field %s must be a scalar or a pointer to a tag or edge struct", s.name, field.Names[0].Name)
+		}
+		for _, name := range field.Names {
+			alias := ngormOptions(field.Tag)["alias"]
+			if alias == "" {
+				alias = strings.ToLower(name.Name)
+			}
+			s.columns = append(s.columns, Column{name: name.Name, alias: alias, typ: typ, entity: entity})
+		}
+	}
+}
+
+// scalarValue 从nebula.Value中取标量，返回判断是否设置的方法和取值的表达式
+func scalarValue(c *Column) (string, string) {
+	switch c.typ {
+	case "string":
+		return "IsSetSVal", "string(v.GetSVal())"
+	case "int64":
+		return "IsSetIVal", "v.GetIVal()"
+	case "int", "int32", "int16", "int8":
+		return "IsSetIVal", c.typ + "(v.GetIVal())"
+	case "float64":
+		return "IsSetFVal", "v.GetFVal()"
+	case "float32":
+		return "IsSetFVal", "float32(v.GetFVal())"
+	case "bool":
+		return "IsSetBVal", "v.GetBVal()"
+	}
+	return "", ""
+}
+
+// funcScan 生成 (ms *XList) Scan(result)，按别名绑定点、边和标量列，值为NULL时保持零值
+func (g *Generator) funcScan(s *Struct) {
+	g.Printlnf(`type ` + s.name + `List []*` + s.name)
+	g.Printlnf(`func (ms *` + s.name + `List) Scan(result *nebula_go.ResultSet) error {`)
+	for i, c := range s.columns {
+		if c.entity {
+			t := g.findStruct(c.typ)
+			if t == nil || (!t.isTag && !t.isEdge) {
+				log.Fatalf("result %s: field %s: %s is not a tag or edge struct", s.name, c.name, c.typ)
+			}
+		} else if is, _ := scalarValue(&s.columns[i]); is == "" {
+			log.Fatalf("result %s: field %s: unsupported type %s", s.name, c.name, c.typ)
+		}
+		g.Printlnf(`	col` + c.name + `, err := lookupCol(result, "` + c.alias + `")`)
+		g.Printlnf(`	if err != nil {`)
+		g.Printlnf(`		return err`)
+		g.Printlnf(`	}`)
+	}
+	g.Printlnf(`	for _, row := range result.GetRows() {`)
+	g.Printlnf(`		m := &` + s.name + `{}`)
+	for i, c := range s.columns {
+		is, val := scalarValue(&s.columns[i])
+		if c.entity && g.findStruct(c.typ).isTag {
+			is, val = "IsSetVVal", "v.GetVVal()"
+		} else if c.entity {
+			is, val = "IsSetEVal", "v.GetEVal()"
+		}
+		g.Printlnf(`		if v := row.Values[col` + c.name + `]; v != nil && v.` + is + `() {`)
+		if !c.entity {
+			g.Printlnf(`			m.` + c.name + ` = ` + val)
+		} else if is == "IsSetVVal" {
+			g.Printlnf(`			m.` + c.name + ` = &` + c.typ + `{}`)
+			g.Printlnf(`			m.` + c.name + `.BindVertex(` + val + `)`)
+		} else {
+			g.Printlnf(`			m.` + c.name + ` = &` + c.typ + `{}`)
+			g.Printlnf(`			m.` + c.name + `.BindEdge(` + val + `)`)
+		}
+		g.Printlnf(`		}`)
+	}
+	g.Printlnf(`		*ms = append(*ms, m)`)
+	g.Printlnf(`	}`)
+	g.Printlnf(`	return nil`)
+	g.Printlnf(`}`)
+}
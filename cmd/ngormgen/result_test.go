@@ -0,0 +1,55 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestBasepoEmbed(t *testing.T) {
+	src := `package p
+
+type PtrResult struct {
+	*basepo.Result
+	U *User ` + "`ngorm:\"alias=u\"`" + `
+}
+
+type ValueResult struct {
+	basepo.Result
+}
+
+type NamedTag struct {
+	Tag basepo.Tag
+}
+
+type OtherTag struct {
+	*other.Tag
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &File{file: file, structs: make([]Struct, 0)}
+	ast.Inspect(file, f.genStruct)
+	want := map[string][2]bool{
+		"PtrResult":   {false, true},
+		"ValueResult": {false, true},
+		"NamedTag":    {false, false},
+		"OtherTag":    {false, false},
+	}
+	for _, s := range f.structs {
+		w, ok := want[s.name]
+		if !ok {
+			continue
+		}
+		if s.isTag != w[0] || s.isResult != w[1] {
+			t.Errorf("%s: isTag %v isResult %v, want %v %v", s.name, s.isTag, s.isResult, w[0], w[1])
+		}
+		delete(want, s.name)
+	}
+	for name := range want {
+		t.Errorf("%s not parsed", name)
+	}
+}
@@ -0,0 +1,85 @@
+package fixture
+
+import "github.com/jeek120/ngorm/basepo"
+
+type User struct {
+	*basepo.Tag
+	Name   string   `idx:"name(10)"` // 名称
+	Passwd string   // 密码
+	Age    int64    // 年龄
+	Groups []*Group `ngorm:"rel=UserGroup"`
+}
+
+type Group struct {
+	*basepo.Tag
+	Name string // 名称
+}
+
+// UserGroup 用户所属群组
+type UserGroup struct {
+	*basepo.Edge `ngorm:"from=User,to=Group"`
+}
+
+// Follow 没有声明起点和终点的边，带属性
+type Follow struct {
+	basepo.Edge
+	Degree int64
+	Note   string
+}
+
+// Person 自环的边，同时预加载点和边
+type Person struct {
+	*basepo.Tag
+	Name      string
+	Friends   []*Person `ngorm:"rel=Knows"`
+	KnowEdges []*Knows  `ngorm:"rel=Knows"`
+}
+
+type Knows struct {
+	*basepo.Edge `ngorm:"from=Person,to=Person"`
+	Since        int64
+}
+
+// Account 带版本和token
+type Account struct {
+	*basepo.Tag
+	Balance int64
+	Version int64  `ngorm:"version"`
+	Token   string `ngorm:"token"`
+}
+
+// Post 组合索引和删除策略
+type Post struct {
+	*basepo.Tag
+	Title string `idx:"title(20),views"`
+	Views int64
+}
+
+type Owns struct {
+	*basepo.Edge `ngorm:"from=Person,to=Post,onDelete=cascade"`
+}
+
+type Mentions struct {
+	*basepo.Edge `ngorm:"from=Post,to=Person,onDelete=restrict"`
+}
+
+type Employee struct {
+	basepo.Tag
+	Title string
+	Level int64
+}
+
+// Staff 组合实体
+type Staff struct {
+	*User
+	Employee
+}
+
+// UserInGroup 查询结果
+type UserInGroup struct {
+	basepo.Result
+	U     *User      `ngorm:"alias=u"`
+	E     *UserGroup `ngorm:"alias=e"`
+	G     *Group     `ngorm:"alias=g"`
+	Count int64      `ngorm:"alias=n"`
+}
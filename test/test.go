@@ -10,6 +10,7 @@ type User struct {
 	Name string				`json:"name" idx:"name(10)"`	// 名称
 	Passwd string
 	Age 	int64
+	Groups	[]*Group	`ngorm:"rel=UserGroup"`	// 预加载的群组
 }
 
 type (
@@ -21,6 +22,6 @@ type (
 
 type (
 	UserGroup struct {
-		*basepo.Edge			// 用户所属群组
+		*basepo.Edge `ngorm:"from=User,to=Group"`			// 用户所属群组
 	}
 )
\ No newline at end of file